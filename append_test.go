@@ -0,0 +1,334 @@
+package goaes_test
+
+import (
+	"bytes"
+	"testing"
+
+	goaes "github.com/fawwazid/go-aes"
+)
+
+// appendMode adapts one mode's append-style and one-shot functions to a
+// common shape.
+type appendMode struct {
+	name    string
+	keySize int
+	seal    func(dst, key, plaintext []byte) ([]byte, error)
+	open    func(dst, key, ciphertext []byte) ([]byte, error)
+	decrypt func(key, ciphertext []byte) ([]byte, error)
+	encrypt func(key, plaintext []byte) ([]byte, error)
+}
+
+func appendModes() []appendMode {
+	aad := []byte("append-aad")
+	nonce := []byte("siv-nonce")
+	return []appendMode{
+		{
+			name: "ECB", keySize: 32,
+			seal:    goaes.SealECB,
+			open:    goaes.OpenECB,
+			decrypt: goaes.DecryptECB,
+			encrypt: goaes.EncryptECB,
+		},
+		{
+			name: "CBC", keySize: 32,
+			seal:    goaes.SealCBC,
+			open:    goaes.OpenCBC,
+			decrypt: goaes.DecryptCBC,
+			encrypt: goaes.EncryptCBC,
+		},
+		{
+			name: "CBCCS3", keySize: 32,
+			seal:    func(dst, k, p []byte) ([]byte, error) { return goaes.SealCBCCS(dst, k, p, goaes.CBCCS3) },
+			open:    func(dst, k, c []byte) ([]byte, error) { return goaes.OpenCBCCS(dst, k, c, goaes.CBCCS3) },
+			decrypt: func(k, c []byte) ([]byte, error) { return goaes.DecryptCBCCS(k, c, goaes.CBCCS3) },
+			encrypt: func(k, p []byte) ([]byte, error) { return goaes.EncryptCBCCS(k, p, goaes.CBCCS3) },
+		},
+		{
+			name: "CTR", keySize: 32,
+			seal:    goaes.SealCTR,
+			open:    goaes.OpenCTR,
+			decrypt: goaes.DecryptCTR,
+			encrypt: goaes.EncryptCTR,
+		},
+		{
+			name: "OFB", keySize: 32,
+			seal:    goaes.SealOFB,
+			open:    goaes.OpenOFB,
+			decrypt: goaes.DecryptOFB,
+			encrypt: goaes.EncryptOFB,
+		},
+		{
+			name: "CFB", keySize: 32,
+			seal:    goaes.SealCFB,
+			open:    goaes.OpenCFB,
+			decrypt: goaes.DecryptCFB,
+			encrypt: goaes.EncryptCFB,
+		},
+		{
+			name: "CFB8", keySize: 32,
+			seal:    func(dst, k, p []byte) ([]byte, error) { return goaes.SealCFBWithSegmentSize(dst, k, p, 8) },
+			open:    func(dst, k, c []byte) ([]byte, error) { return goaes.OpenCFBWithSegmentSize(dst, k, c, 8) },
+			decrypt: func(k, c []byte) ([]byte, error) { return goaes.DecryptCFBWithSegmentSize(k, c, 8) },
+			encrypt: func(k, p []byte) ([]byte, error) { return goaes.EncryptCFBWithSegmentSize(k, p, 8) },
+		},
+		{
+			name: "XTS", keySize: 64,
+			seal:    func(dst, k, p []byte) ([]byte, error) { return goaes.SealXTS(dst, k, p, 7) },
+			open:    func(dst, k, c []byte) ([]byte, error) { return goaes.OpenXTS(dst, k, c, 7) },
+			decrypt: func(k, c []byte) ([]byte, error) { return goaes.DecryptXTS(k, c, 7) },
+			encrypt: func(k, p []byte) ([]byte, error) { return goaes.EncryptXTS(k, p, 7) },
+		},
+		{
+			name: "GCM", keySize: 32,
+			seal:    func(dst, k, p []byte) ([]byte, error) { return goaes.SealGCM(dst, k, p, aad) },
+			open:    func(dst, k, c []byte) ([]byte, error) { return goaes.OpenGCM(dst, k, c, aad) },
+			decrypt: func(k, c []byte) ([]byte, error) { return goaes.DecryptGCM(k, c, aad) },
+			encrypt: func(k, p []byte) ([]byte, error) { return goaes.EncryptGCM(k, p, aad) },
+		},
+		{
+			name: "GCMCommitting", keySize: 32,
+			seal:    func(dst, k, p []byte) ([]byte, error) { return goaes.SealGCMCommitting(dst, k, p, aad) },
+			open:    func(dst, k, c []byte) ([]byte, error) { return goaes.OpenGCMCommitting(dst, k, c, aad) },
+			decrypt: func(k, c []byte) ([]byte, error) { return goaes.DecryptGCMCommitting(k, c, aad) },
+			encrypt: func(k, p []byte) ([]byte, error) { return goaes.EncryptGCMCommitting(k, p, aad) },
+		},
+		{
+			name: "XAESGCM", keySize: 32,
+			seal:    func(dst, k, p []byte) ([]byte, error) { return goaes.SealXAESGCM(dst, k, p, aad) },
+			open:    func(dst, k, c []byte) ([]byte, error) { return goaes.OpenXAESGCM(dst, k, c, aad) },
+			decrypt: func(k, c []byte) ([]byte, error) { return goaes.DecryptXAESGCM(k, c, aad) },
+			encrypt: func(k, p []byte) ([]byte, error) { return goaes.EncryptXAESGCM(k, p, aad) },
+		},
+		{
+			name: "GCMSIV", keySize: 32,
+			seal:    func(dst, k, p []byte) ([]byte, error) { return goaes.SealGCMSIV(dst, k, p, aad) },
+			open:    func(dst, k, c []byte) ([]byte, error) { return goaes.OpenGCMSIV(dst, k, c, aad) },
+			decrypt: func(k, c []byte) ([]byte, error) { return goaes.DecryptGCMSIV(k, c, aad) },
+			encrypt: func(k, p []byte) ([]byte, error) { return goaes.EncryptGCMSIV(k, p, aad) },
+		},
+		{
+			name: "CCM", keySize: 32,
+			seal:    func(dst, k, p []byte) ([]byte, error) { return goaes.SealCCM(dst, k, p, aad, 12, 16) },
+			open:    func(dst, k, c []byte) ([]byte, error) { return goaes.OpenCCM(dst, k, c, aad, 12, 16) },
+			decrypt: func(k, c []byte) ([]byte, error) { return goaes.DecryptCCM(k, c, aad, 12, 16) },
+			encrypt: func(k, p []byte) ([]byte, error) { return goaes.EncryptCCM(k, p, aad, 12, 16) },
+		},
+		{
+			name: "EAX", keySize: 32,
+			seal:    func(dst, k, p []byte) ([]byte, error) { return goaes.SealEAX(dst, k, p, aad, 16, 16) },
+			open:    func(dst, k, c []byte) ([]byte, error) { return goaes.OpenEAX(dst, k, c, aad, 16, 16) },
+			decrypt: func(k, c []byte) ([]byte, error) { return goaes.DecryptEAX(k, c, aad, 16, 16) },
+			encrypt: func(k, p []byte) ([]byte, error) { return goaes.EncryptEAX(k, p, aad, 16, 16) },
+		},
+		{
+			name: "OCB", keySize: 32,
+			seal:    func(dst, k, p []byte) ([]byte, error) { return goaes.SealOCB(dst, k, p, aad) },
+			open:    func(dst, k, c []byte) ([]byte, error) { return goaes.OpenOCB(dst, k, c, aad) },
+			decrypt: func(k, c []byte) ([]byte, error) { return goaes.DecryptOCB(k, c, aad) },
+			encrypt: func(k, p []byte) ([]byte, error) { return goaes.EncryptOCB(k, p, aad) },
+		},
+		{
+			name: "SIV", keySize: 64,
+			seal:    func(dst, k, p []byte) ([]byte, error) { return goaes.SealSIV(dst, k, p, nonce, aad) },
+			open:    func(dst, k, c []byte) ([]byte, error) { return goaes.OpenSIV(dst, k, c, nonce, aad) },
+			decrypt: func(k, c []byte) ([]byte, error) { return goaes.DecryptSIV(k, c, nonce, aad) },
+			encrypt: func(k, p []byte) ([]byte, error) { return goaes.EncryptSIV(k, p, nonce, aad) },
+		},
+		{
+			name: "CBCHMAC", keySize: 64,
+			seal:    func(dst, k, p []byte) ([]byte, error) { return goaes.SealCBCHMAC(dst, k, p, aad) },
+			open:    func(dst, k, c []byte) ([]byte, error) { return goaes.OpenCBCHMAC(dst, k, c, aad) },
+			decrypt: func(k, c []byte) ([]byte, error) { return goaes.DecryptCBCHMAC(k, c, aad) },
+			encrypt: func(k, p []byte) ([]byte, error) { return goaes.EncryptCBCHMAC(k, p, aad) },
+		},
+		{
+			name: "CTRHMAC", keySize: 32,
+			seal:    func(dst, k, p []byte) ([]byte, error) { return goaes.SealCTRHMAC(dst, k, p, aad) },
+			open:    func(dst, k, c []byte) ([]byte, error) { return goaes.OpenCTRHMAC(dst, k, c, aad) },
+			decrypt: func(k, c []byte) ([]byte, error) { return goaes.DecryptCTRHMAC(k, c, aad) },
+			encrypt: func(k, p []byte) ([]byte, error) { return goaes.EncryptCTRHMAC(k, p, aad) },
+		},
+		{
+			name: "CFBHMAC", keySize: 32,
+			seal:    func(dst, k, p []byte) ([]byte, error) { return goaes.SealCFBHMAC(dst, k, p, aad) },
+			open:    func(dst, k, c []byte) ([]byte, error) { return goaes.OpenCFBHMAC(dst, k, c, aad) },
+			decrypt: func(k, c []byte) ([]byte, error) { return goaes.DecryptCFBHMAC(k, c, aad) },
+			encrypt: func(k, p []byte) ([]byte, error) { return goaes.EncryptCFBHMAC(k, p, aad) },
+		},
+		{
+			name: "OFBHMAC", keySize: 32,
+			seal:    func(dst, k, p []byte) ([]byte, error) { return goaes.SealOFBHMAC(dst, k, p, aad) },
+			open:    func(dst, k, c []byte) ([]byte, error) { return goaes.OpenOFBHMAC(dst, k, c, aad) },
+			decrypt: func(k, c []byte) ([]byte, error) { return goaes.DecryptOFBHMAC(k, c, aad) },
+			encrypt: func(k, p []byte) ([]byte, error) { return goaes.EncryptOFBHMAC(k, p, aad) },
+		},
+	}
+}
+
+func TestAppend_Prefix(t *testing.T) {
+	plaintext := []byte("append-style output keeps the existing prefix!")
+
+	for _, m := range appendModes() {
+		t.Run(m.name, func(t *testing.T) {
+			key := bytes.Repeat([]byte{0x42}, m.keySize)
+			prefix := []byte("hdr:")
+
+			sealed, err := m.seal(append([]byte(nil), prefix...), key, plaintext)
+			if err != nil {
+				t.Fatalf("seal failed: %v", err)
+			}
+			if !bytes.HasPrefix(sealed, prefix) {
+				t.Fatalf("seal clobbered dst prefix: %q", sealed[:len(prefix)])
+			}
+
+			decrypted, err := m.decrypt(key, sealed[len(prefix):])
+			if err != nil {
+				t.Fatalf("decrypt of sealed output failed: %v", err)
+			}
+			if !bytes.Equal(decrypted, plaintext) {
+				t.Fatalf("decrypt mismatch: got %q", decrypted)
+			}
+
+			opened, err := m.open(append([]byte(nil), prefix...), key, sealed[len(prefix):])
+			if err != nil {
+				t.Fatalf("open failed: %v", err)
+			}
+			if !bytes.Equal(opened, append(prefix, plaintext...)) {
+				t.Fatalf("open mismatch: got %q", opened)
+			}
+		})
+	}
+}
+
+func TestAppend_InPlace(t *testing.T) {
+	plaintext := []byte("in-place encryption reuses the caller's buffer.")
+
+	for _, m := range appendModes() {
+		t.Run(m.name, func(t *testing.T) {
+			key := bytes.Repeat([]byte{0x24}, m.keySize)
+
+			buf := make([]byte, len(plaintext), len(plaintext)+256)
+			copy(buf, plaintext)
+
+			sealed, err := m.seal(buf[:0], key, buf)
+			if err != nil {
+				t.Fatalf("in-place seal failed: %v", err)
+			}
+			if &sealed[0] != &buf[0] {
+				t.Fatal("in-place seal did not reuse the buffer")
+			}
+
+			decrypted, err := m.decrypt(key, sealed)
+			if err != nil {
+				t.Fatalf("decrypt of in-place output failed: %v", err)
+			}
+			if !bytes.Equal(decrypted, plaintext) {
+				t.Fatalf("decrypt mismatch: got %q", decrypted)
+			}
+
+			opened, err := m.open(sealed[:0], key, sealed)
+			if err != nil {
+				t.Fatalf("in-place open failed: %v", err)
+			}
+			if !bytes.Equal(opened, plaintext) {
+				t.Fatalf("in-place open mismatch: got %q", opened)
+			}
+			if &opened[0] != &buf[0] {
+				t.Fatal("in-place open did not reuse the buffer")
+			}
+		})
+	}
+}
+
+func TestAppend_ShiftedOverlap(t *testing.T) {
+	plaintext := []byte("input that starts partway into the output buffer")
+
+	for _, m := range appendModes() {
+		t.Run(m.name, func(t *testing.T) {
+			key := bytes.Repeat([]byte{0x11}, m.keySize)
+
+			// The input sits 5 bytes into the buffer that receives the output.
+			buf := make([]byte, 5+len(plaintext), len(plaintext)+256)
+			copy(buf[5:], plaintext)
+
+			sealed, err := m.seal(buf[:0], key, buf[5:])
+			if err != nil {
+				t.Fatalf("seal failed: %v", err)
+			}
+			decrypted, err := m.decrypt(key, sealed)
+			if err != nil {
+				t.Fatalf("decrypt failed: %v", err)
+			}
+			if !bytes.Equal(decrypted, plaintext) {
+				t.Fatalf("decrypt mismatch: got %q", decrypted)
+			}
+		})
+	}
+}
+
+func TestAppend_AllocsDoNotScale(t *testing.T) {
+	for _, m := range appendModes() {
+		t.Run(m.name, func(t *testing.T) {
+			key := bytes.Repeat([]byte{0x33}, m.keySize)
+
+			allocs := func(size int) (seal, encrypt float64) {
+				plaintext := bytes.Repeat([]byte{'a'}, size)
+				buf := make([]byte, 0, size+256)
+				seal = testing.AllocsPerRun(20, func() {
+					if _, err := m.seal(buf, key, plaintext); err != nil {
+						t.Fatal(err)
+					}
+				})
+				encrypt = testing.AllocsPerRun(20, func() {
+					if _, err := m.encrypt(key, plaintext); err != nil {
+						t.Fatal(err)
+					}
+				})
+				return seal, encrypt
+			}
+
+			small, smallEncrypt := allocs(64)
+			large, _ := allocs(64 << 10)
+			if small != large {
+				t.Errorf("seal allocations depend on message size: %v at 64 B, %v at 64 KiB", small, large)
+			}
+			if small >= smallEncrypt {
+				t.Errorf("seal into a preallocated buffer made %v allocations, encrypt made %v", small, smallEncrypt)
+			}
+		})
+	}
+}
+
+func TestSealGCM_ZeroAllocWithKeyCache(t *testing.T) {
+	if err := goaes.SetKeyCacheSize(1); err != nil {
+		t.Fatalf("SetKeyCacheSize failed: %v", err)
+	}
+	defer goaes.SetKeyCacheSize(0)
+
+	key := bytes.Repeat([]byte{0x55}, 32)
+	plaintext := bytes.Repeat([]byte{'p'}, 1024)
+	aad := []byte("aad")
+
+	sealed := make([]byte, 0, len(plaintext)+28)
+	opened := make([]byte, 0, len(plaintext)+16)
+
+	ct, err := goaes.SealGCM(sealed, key, plaintext, aad)
+	if err != nil {
+		t.Fatalf("SealGCM failed: %v", err)
+	}
+
+	if n := testing.AllocsPerRun(100, func() {
+		if _, err := goaes.SealGCM(sealed, key, plaintext, aad); err != nil {
+			t.Fatal(err)
+		}
+	}); n != 0 {
+		t.Errorf("SealGCM made %v allocations, want 0", n)
+	}
+	if n := testing.AllocsPerRun(100, func() {
+		if _, err := goaes.OpenGCM(opened, key, ct, aad); err != nil {
+			t.Fatal(err)
+		}
+	}); n != 0 {
+		t.Errorf("OpenGCM made %v allocations, want 0", n)
+	}
+}
@@ -1,6 +1,7 @@
 package goaes
 
 import (
+	"crypto/aes"
 	"crypto/cipher"
 	"crypto/rand"
 	"errors"
@@ -21,27 +22,7 @@ import (
 //
 // Returns: IV prepended to ciphertext (iv||ciphertext).
 func EncryptCBC(key, plaintext []byte) ([]byte, error) {
-	block, err := newCipherBlock(key)
-	if err != nil {
-		return nil, err
-	}
-
-	bs := block.BlockSize()
-	padded := pkcs7Pad(plaintext, bs)
-
-	iv := make([]byte, bs)
-	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
-		return nil, err
-	}
-
-	ct := make([]byte, len(padded))
-	mode := cipher.NewCBCEncrypter(block, iv)
-	mode.CryptBlocks(ct, padded)
-
-	out := make([]byte, 0, len(iv)+len(ct))
-	out = append(out, iv...)
-	out = append(out, ct...)
-	return out, nil
+	return SealCBC(nil, key, plaintext)
 }
 
 // DecryptCBC decrypts data produced by EncryptCBC.
@@ -53,26 +34,107 @@ func EncryptCBC(key, plaintext []byte) ([]byte, error) {
 //
 // Returns: decrypted plaintext (unpadded).
 func DecryptCBC(key, ciphertext []byte) ([]byte, error) {
-	block, err := newCipherBlock(key)
+	return OpenCBC(nil, key, ciphertext)
+}
+
+// SealCBC is the append-style form of EncryptCBC: it appends iv||ciphertext
+// to dst and returns the updated slice. Padding is written directly into the
+// output, and nothing is allocated for it when dst has enough spare capacity
+// (len(plaintext)+32 bytes). dst may overlap plaintext, e.g. buf[:0] to
+// encrypt buf in place.
+func SealCBC(dst, key, plaintext []byte) ([]byte, error) {
+	if err := validateKeySize(key); err != nil {
+		return nil, err
+	}
+
+	const bs = aes.BlockSize
+	ret, out := sliceForAppend(dst, bs+pkcs7PaddedLen(len(plaintext), bs))
+	iv, body := out[:bs], out[bs:]
+	pkcs7Fill(body, copy(body, plaintext))
+
+	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
+		return nil, err
+	}
+
+	mode, err := NewCBCEncrypter(key, iv)
 	if err != nil {
 		return nil, err
 	}
+	mode.CryptBlocks(body, body)
+	return ret, nil
+}
+
+// OpenCBC is the append-style form of DecryptCBC: it appends the unpadded
+// plaintext to dst and returns the updated slice. dst may overlap
+// ciphertext, e.g. buf[:0] to decrypt buf in place.
+func OpenCBC(dst, key, ciphertext []byte) ([]byte, error) {
+	if err := validateKeySize(key); err != nil {
+		return nil, err
+	}
 
-	bs := block.BlockSize()
+	const bs = aes.BlockSize
 	if len(ciphertext) < bs {
 		return nil, errors.New("ciphertext too short")
 	}
 
-	iv := ciphertext[:bs]
-	ct := ciphertext[bs:]
-
-	if len(ct)%bs != 0 {
+	body := ciphertext[bs:]
+	if len(body)%bs != 0 {
 		return nil, errors.New("ciphertext is not a multiple of block size")
 	}
 
-	pt := make([]byte, len(ct))
-	mode := cipher.NewCBCDecrypter(block, iv)
-	mode.CryptBlocks(pt, ct)
+	// The decrypter copies the IV, so the output may overwrite it.
+	mode, err := NewCBCDecrypter(key, ciphertext[:bs])
+	if err != nil {
+		return nil, err
+	}
+	ret, out := sliceForAppend(dst, len(body))
+	if anyOverlap(out, ciphertext) {
+		body = out[:copy(out, body)]
+	}
+	mode.CryptBlocks(out, body)
+
+	pt, err := pkcs7Unpad(out, bs)
+	if err != nil {
+		clear(out)
+		return nil, err
+	}
+	return ret[:len(dst)+len(pt)], nil
+}
+
+// NewCBCEncrypter returns AES in CBC mode as an encrypting cipher.BlockMode,
+// for use wherever cipher.NewCBCEncrypter would be used. No padding is
+// applied; CryptBlocks panics on input that is not a multiple of 16 bytes.
+//
+// NIST SP 800-38A Warning: The IV must be unpredictable (random) for every
+// message. No integrity is provided.
+//
+// Parameters:
+//   - key: 16, 24, or 32 bytes (AES-128, 192, or 256).
+//   - iv: 16 bytes. It is copied.
+func NewCBCEncrypter(key, iv []byte) (cipher.BlockMode, error) {
+	block, err := newCipherBlock(key)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateIVSize(iv); err != nil {
+		return nil, err
+	}
+	return cipher.NewCBCEncrypter(block, iv), nil
+}
 
-	return pkcs7Unpad(pt, bs)
+// NewCBCDecrypter returns AES in CBC mode as a decrypting cipher.BlockMode.
+// No padding is removed.
+//
+// Parameters:
+//   - key: same key used for encryption.
+//   - iv: same IV used for encryption. It is copied.
+func NewCBCDecrypter(key, iv []byte) (cipher.BlockMode, error) {
+	block, err := newCipherBlock(key)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateIVSize(iv); err != nil {
+		return nil, err
+	}
+	return cipher.NewCBCDecrypter(block, iv), nil
 }
@@ -54,3 +54,40 @@ func TestAESCBC_InvalidKey(t *testing.T) {
 		t.Error("expected error for invalid key size in DecryptCBC")
 	}
 }
+
+func TestAESCBC_NewCBC(t *testing.T) {
+	key := bytes.Repeat([]byte{0x05}, 16)
+	iv := bytes.Repeat([]byte{0x06}, 16)
+	// Two full blocks: BlockMode does not pad, so PKCS#7 adds a third.
+	plaintext := []byte("exactly thirty-two bytes of text")
+	padded := append(append([]byte{}, plaintext...), bytes.Repeat([]byte{16}, 16)...)
+
+	enc, err := goaes.NewCBCEncrypter(key, iv)
+	if err != nil {
+		t.Fatalf("NewCBCEncrypter failed: %v", err)
+	}
+	ct := make([]byte, len(padded))
+	enc.CryptBlocks(ct, padded)
+
+	pt, err := goaes.DecryptCBC(key, append(append([]byte{}, iv...), ct...))
+	if err != nil || !bytes.Equal(pt, plaintext) {
+		t.Fatalf("DecryptCBC of BlockMode output = %q, %v", pt, err)
+	}
+
+	dec, err := goaes.NewCBCDecrypter(key, iv)
+	if err != nil {
+		t.Fatalf("NewCBCDecrypter failed: %v", err)
+	}
+	out := make([]byte, len(ct))
+	dec.CryptBlocks(out, ct)
+	if !bytes.Equal(out, padded) {
+		t.Fatalf("CryptBlocks = %x, want %x", out, padded)
+	}
+
+	if _, err := goaes.NewCBCEncrypter(key, iv[:8]); err == nil {
+		t.Fatal("expected error for 8-byte IV")
+	}
+	if _, err := goaes.NewCBCDecrypter(make([]byte, 20), iv); err == nil {
+		t.Fatal("expected error for invalid key size")
+	}
+}
@@ -0,0 +1,184 @@
+package goaes
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"io"
+)
+
+// CBCCSVariant selects the ciphertext-stealing variant for EncryptCBCCS and
+// DecryptCBCCS. The variants differ only in the order of the last two blocks.
+type CBCCSVariant int
+
+const (
+	// CBCCS1 keeps the partial penultimate block before the final block.
+	CBCCS1 CBCCSVariant = iota + 1
+	// CBCCS2 swaps the last two blocks only when the final block is partial,
+	// so block-aligned messages match plain CBC.
+	CBCCS2
+	// CBCCS3 always swaps the last two blocks; this is the Kerberos (RFC 3962) variant.
+	CBCCS3
+)
+
+// EncryptCBCCS encrypts plaintext using AES-CBC with ciphertext stealing.
+//
+// NIST SP 800-38A Addendum: CBC-CS1, CBC-CS2 and CBC-CS3 need no padding, so
+// the ciphertext is exactly as long as the plaintext (plus the IV) and there
+// is no padding oracle.
+//
+// NIST SP 800-38A Warning: This mode provides Confidentiality ONLY.
+// Recommendation: Use EncryptGCM (AEAD) instead.
+//
+// Parameters:
+//   - key: 16, 24, or 32 bytes (AES-128, 192, or 256).
+//   - plaintext: Data to be encrypted (at least 16 bytes).
+//   - variant: CBCCS1, CBCCS2 or CBCCS3.
+//
+// Returns: IV prepended to ciphertext (iv||ciphertext).
+func EncryptCBCCS(key, plaintext []byte, variant CBCCSVariant) ([]byte, error) {
+	return SealCBCCS(nil, key, plaintext, variant)
+}
+
+// DecryptCBCCS decrypts data produced by EncryptCBCCS.
+// It expects the IV to be prepended to the ciphertext.
+//
+// Parameters:
+//   - key: same key used for encryption.
+//   - ciphertext: iv||ciphertext.
+//   - variant: same variant used for encryption.
+//
+// Returns: decrypted plaintext.
+func DecryptCBCCS(key, ciphertext []byte, variant CBCCSVariant) ([]byte, error) {
+	return OpenCBCCS(nil, key, ciphertext, variant)
+}
+
+// SealCBCCS is the append-style form of EncryptCBCCS: it appends
+// iv||ciphertext to dst and returns the updated slice. dst may overlap
+// plaintext, e.g. buf[:0] to encrypt buf in place.
+func SealCBCCS(dst, key, plaintext []byte, variant CBCCSVariant) ([]byte, error) {
+	block, err := newCipherBlock(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateCBCCSVariant(variant); err != nil {
+		return nil, err
+	}
+
+	bs := block.BlockSize()
+	if len(plaintext) < bs {
+		return nil, errors.New("plaintext must be at least one block for CBC ciphertext stealing")
+	}
+
+	ret, out := sliceForAppend(dst, bs+len(plaintext))
+	iv, body := out[:bs], out[bs:]
+	copy(body, plaintext)
+
+	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
+		return nil, err
+	}
+
+	enc := cipher.NewCBCEncrypter(block, iv)
+	n := (len(body) + bs - 1) / bs
+	if n == 1 {
+		enc.CryptBlocks(body, body)
+		return ret, nil
+	}
+
+	// Encrypt P_1..P_{n-1} in place, then the zero-padded final block
+	// (CS1 form), chaining from C_{n-1}.
+	d := len(body) - (n-1)*bs
+	enc.CryptBlocks(body[:(n-1)*bs], body[:(n-1)*bs])
+
+	var last, partial [aes.BlockSize]byte
+	copy(last[:], body[(n-1)*bs:])
+	enc.CryptBlocks(last[:], last[:]) // C_n
+
+	tail := body[(n-2)*bs:] // C*_{n-1} is tail[:d]
+	if cbccsSwap(variant, d, bs) {
+		copy(partial[:], tail[:d])
+		copy(tail, last[:])
+		copy(tail[bs:], partial[:d])
+	} else {
+		copy(tail[d:], last[:])
+	}
+	return ret, nil
+}
+
+// OpenCBCCS is the append-style form of DecryptCBCCS: it appends the
+// plaintext to dst and returns the updated slice. dst may overlap
+// ciphertext, e.g. buf[:0] to decrypt buf in place.
+func OpenCBCCS(dst, key, ciphertext []byte, variant CBCCSVariant) ([]byte, error) {
+	block, err := newCipherBlock(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateCBCCSVariant(variant); err != nil {
+		return nil, err
+	}
+
+	bs := block.BlockSize()
+	if len(ciphertext) < 2*bs {
+		return nil, errors.New("ciphertext too short")
+	}
+
+	// The decrypter copies the IV, so the output may overwrite it.
+	dec := cipher.NewCBCDecrypter(block, ciphertext[:bs])
+	ct := ciphertext[bs:]
+	ret, pt := sliceForAppend(dst, len(ct))
+	if anyOverlap(pt, ciphertext) {
+		ct = pt[:copy(pt, ct)]
+	}
+
+	n := (len(ct) + bs - 1) / bs
+	if n == 1 {
+		dec.CryptBlocks(pt, ct)
+		return ret, nil
+	}
+
+	// Recover C*_{n-1} and C_n in CS1 order.
+	d := len(ct) - (n-1)*bs
+	tail := ct[(n-2)*bs:]
+	var partial, last, z [aes.BlockSize]byte
+	if cbccsSwap(variant, d, bs) {
+		copy(last[:], tail[:bs])
+		copy(partial[:], tail[bs:])
+	} else {
+		copy(partial[:], tail[:d])
+		copy(last[:], tail[d:])
+	}
+
+	// D(C_n) = P_n||0 xor C_{n-1}, so its tail completes C_{n-1}.
+	block.Decrypt(z[:], last[:])
+	subtle.XORBytes(pt[(n-1)*bs:], z[:d], partial[:d])
+	copy(partial[d:], z[d:])
+
+	dec.CryptBlocks(pt[:(n-2)*bs], ct[:(n-2)*bs])
+	dec.CryptBlocks(pt[(n-2)*bs:(n-1)*bs], partial[:])
+	return ret, nil
+}
+
+// cbccsSwap reports whether the variant outputs C_n before C*_{n-1} for a
+// final block of d bytes.
+func cbccsSwap(variant CBCCSVariant, d, bs int) bool {
+	switch variant {
+	case CBCCS2:
+		return d != bs
+	case CBCCS3:
+		return true
+	default:
+		return false
+	}
+}
+
+// validateCBCCSVariant checks that variant is CBCCS1, CBCCS2 or CBCCS3.
+func validateCBCCSVariant(variant CBCCSVariant) error {
+	if variant < CBCCS1 || variant > CBCCS3 {
+		return errors.New("invalid CBC ciphertext stealing variant: must be CBCCS1, CBCCS2, or CBCCS3")
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package goaes_test
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"testing"
+
+	goaes "github.com/fawwazid/go-aes"
+)
+
+func TestAESCBCCS_EncryptDecrypt(t *testing.T) {
+	msg := []byte("Bright vixens jump; dozy fowl quack. Quick wafting zephyrs vex bold Jim.")
+
+	for _, k := range []int{16, 24, 32} {
+		key := make([]byte, k)
+		for i := 0; i < k; i++ {
+			key[i] = byte(i)
+		}
+
+		for _, variant := range []goaes.CBCCSVariant{goaes.CBCCS1, goaes.CBCCS2, goaes.CBCCS3} {
+			for _, n := range []int{16, 17, 31, 32, 33, 48, len(msg)} {
+				plaintext := msg[:n]
+
+				ct, err := goaes.EncryptCBCCS(key, plaintext, variant)
+				if err != nil {
+					t.Fatalf("encrypt failed for key len %d, CS%d, len %d: %v", k, variant, n, err)
+				}
+				if len(ct) != 16+n {
+					t.Fatalf("ciphertext len = %d, want %d (CS%d)", len(ct), 16+n, variant)
+				}
+
+				pt, err := goaes.DecryptCBCCS(key, ct, variant)
+				if err != nil {
+					t.Fatalf("decrypt failed for key len %d, CS%d, len %d: %v", k, variant, n, err)
+				}
+				if !bytes.Equal(pt, plaintext) {
+					t.Fatalf("plaintext mismatch for key len %d, CS%d, len %d", k, variant, n)
+				}
+			}
+		}
+	}
+}
+
+func TestAESCBCCS_VariantOrdering(t *testing.T) {
+	key := make([]byte, 16)
+	plaintext := []byte("exactly forty bytes of plaintext here!!!")[:40]
+
+	cs1, err := goaes.EncryptCBCCS(key, plaintext, goaes.CBCCS1)
+	if err != nil {
+		t.Fatalf("encrypt failed: %v", err)
+	}
+
+	// The same IV under CS2/CS3 must yield CS1 with the last two blocks swapped.
+	iv, body := cs1[:16], cs1[16:]
+	d := len(body) - 32
+	swapped := append(append(append([]byte{}, body[:16]...), body[16+d:]...), body[16:16+d]...)
+
+	for _, variant := range []goaes.CBCCSVariant{goaes.CBCCS2, goaes.CBCCS3} {
+		pt, err := goaes.DecryptCBCCS(key, append(append([]byte{}, iv...), swapped...), variant)
+		if err != nil {
+			t.Fatalf("CS%d decrypt failed: %v", variant, err)
+		}
+		if !bytes.Equal(pt, plaintext) {
+			t.Fatalf("CS%d did not accept swapped CS1 ciphertext", variant)
+		}
+	}
+
+	// For block-aligned input, CS1 and CS2 are plain CBC without padding.
+	aligned := plaintext[:32]
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatalf("aes.NewCipher failed: %v", err)
+	}
+	for _, variant := range []goaes.CBCCSVariant{goaes.CBCCS1, goaes.CBCCS2} {
+		ct, err := goaes.EncryptCBCCS(key, aligned, variant)
+		if err != nil {
+			t.Fatalf("CS%d encrypt failed: %v", variant, err)
+		}
+		want := make([]byte, len(aligned))
+		cipher.NewCBCEncrypter(block, ct[:16]).CryptBlocks(want, aligned)
+		if !bytes.Equal(ct[16:], want) {
+			t.Fatalf("CS%d aligned ciphertext is not plain CBC", variant)
+		}
+	}
+}
+
+// TestAESCBCCS_RFC3962 checks CBC-CS3 against the RFC 3962 Appendix B
+// Kerberos vectors, which use a zero IV.
+func TestAESCBCCS_RFC3962(t *testing.T) {
+	key := mustHex(t, "636869636b656e207465726979616b69")
+	input := []byte("I would like the General Gau's Chicken, please, and wonton soup.")
+
+	tests := []struct {
+		n      int
+		output string
+	}{
+		{17, "c6353568f2bf8cb4d8a580362da7ff7f97"},
+		{31, "fc00783e0efdb2c1d445d4c8eff7ed2297687268d6ecccc0c07b25e25ecfe5"},
+		{32, "39312523a78662d5be7fcbcc98ebf5a897687268d6ecccc0c07b25e25ecfe584"},
+		{47, "97687268d6ecccc0c07b25e25ecfe584b3fffd940c16a18c1b5549d2f838029e39312523a78662d5be7fcbcc98ebf5"},
+		{48, "97687268d6ecccc0c07b25e25ecfe5849dad8bbb96c4cdc03bc103e1a194bbd839312523a78662d5be7fcbcc98ebf5a8"},
+		{64, "97687268d6ecccc0c07b25e25ecfe58439312523a78662d5be7fcbcc98ebf5a84807efe836ee89a526730dbc2f7bc8409dad8bbb96c4cdc03bc103e1a194bbd8"},
+	}
+
+	for _, tt := range tests {
+		in := append(make([]byte, 16), mustHex(t, tt.output)...)
+		pt, err := goaes.DecryptCBCCS(key, in, goaes.CBCCS3)
+		if err != nil {
+			t.Fatalf("len %d: decrypt failed: %v", tt.n, err)
+		}
+		if !bytes.Equal(pt, input[:tt.n]) {
+			t.Fatalf("len %d: plaintext = %q, want %q", tt.n, pt, input[:tt.n])
+		}
+	}
+}
+
+func TestAESCBCCS_InvalidInput(t *testing.T) {
+	key := make([]byte, 16)
+
+	if _, err := goaes.EncryptCBCCS([]byte("invalid-key"), make([]byte, 16), goaes.CBCCS3); err == nil {
+		t.Error("expected error for invalid key size in EncryptCBCCS")
+	}
+	if _, err := goaes.EncryptCBCCS(key, make([]byte, 15), goaes.CBCCS3); err == nil {
+		t.Error("expected error for plaintext shorter than one block")
+	}
+	if _, err := goaes.EncryptCBCCS(key, make([]byte, 16), goaes.CBCCSVariant(0)); err == nil {
+		t.Error("expected error for invalid variant")
+	}
+	if _, err := goaes.DecryptCBCCS(key, make([]byte, 31), goaes.CBCCS3); err == nil {
+		t.Error("expected error for short ciphertext in DecryptCBCCS")
+	}
+}
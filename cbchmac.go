@@ -0,0 +1,173 @@
+package goaes
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"hash"
+)
+
+// cbcHMACNonceSize is the CBC IV length used as the AEAD nonce.
+const cbcHMACNonceSize = 16
+
+// EncryptCBCHMAC encrypts plaintext using the AES-CBC-HMAC-SHA2 composite AEAD
+// (RFC 7518, Section 5.2: A128CBC-HS256, A192CBC-HS384, A256CBC-HS512).
+//
+// Encrypt-then-MAC: the IV, ciphertext and AAD are authenticated with HMAC,
+// and the tag is verified before any padding is checked, so it is not
+// vulnerable to padding oracles. Use it when GCM is not an option.
+//
+// Parameters:
+//   - key: 32, 48 or 64 bytes (A128CBC-HS256, A192CBC-HS384 or A256CBC-HS512).
+//     The first half is the MAC key and the second half the AES key.
+//   - plaintext: Data to be encrypted.
+//   - aad: Additional Authenticated Data (optional, can be nil).
+//
+// Returns: iv||ciphertext||tag
+func EncryptCBCHMAC(key, plaintext, aad []byte) ([]byte, error) {
+	return SealCBCHMAC(nil, key, plaintext, aad)
+}
+
+// DecryptCBCHMAC decrypts data produced by EncryptCBCHMAC.
+// It expects the IV to be prepended to the ciphertext.
+//
+// Parameters:
+//   - key: same key used for encryption.
+//   - ciphertext: iv||ciphertext||tag.
+//   - aad: same additional data used for encryption.
+//
+// Returns: decrypted plaintext.
+func DecryptCBCHMAC(key, ciphertext, aad []byte) ([]byte, error) {
+	return OpenCBCHMAC(nil, key, ciphertext, aad)
+}
+
+// SealCBCHMAC is the append-style form of EncryptCBCHMAC: it appends
+// iv||ciphertext||tag to dst and returns the updated slice. dst may overlap
+// plaintext, e.g. buf[:0] to encrypt buf in place; aad must not overlap dst.
+func SealCBCHMAC(dst, key, plaintext, aad []byte) ([]byte, error) {
+	aead, err := newCBCHMAC(key)
+	if err != nil {
+		return nil, err
+	}
+	return sealPrefixed(dst, aead, plaintext, aad)
+}
+
+// OpenCBCHMAC is the append-style form of DecryptCBCHMAC: it appends the
+// plaintext to dst and returns the updated slice. dst may overlap
+// ciphertext, e.g. buf[:0] to decrypt buf in place.
+func OpenCBCHMAC(dst, key, ciphertext, aad []byte) ([]byte, error) {
+	aead, err := newCBCHMAC(key)
+	if err != nil {
+		return nil, err
+	}
+	return openPrefixed(dst, aead, ciphertext, aad)
+}
+
+// NewCBCHMAC returns the AES-CBC-HMAC-SHA2 composite AEAD as a cipher.AEAD.
+// The variant is selected by key length: 32 bytes for A128CBC-HS256, 48 for
+// A192CBC-HS384 and 64 for A256CBC-HS512. The nonce is the 16-byte CBC IV,
+// which must be unpredictable (random) for every message.
+func NewCBCHMAC(key []byte) (cipher.AEAD, error) {
+	return newCBCHMAC(key)
+}
+
+// cbcHMAC implements cipher.AEAD for AES-CBC-HMAC-SHA2.
+type cbcHMAC struct {
+	block   cipher.Block
+	macKey  []byte
+	newHash func() hash.Hash
+	tagSize int
+}
+
+// newCBCHMAC splits the key and selects the hash and tag size by key length.
+func newCBCHMAC(key []byte) (*cbcHMAC, error) {
+	var newHash func() hash.Hash
+	switch len(key) {
+	case 32:
+		newHash = sha256.New
+	case 48:
+		newHash = sha512.New384
+	case 64:
+		newHash = sha512.New
+	default:
+		return nil, errors.New("invalid CBC-HMAC key size: must be 32, 48, or 64 bytes")
+	}
+
+	half := len(key) / 2
+	block, err := aes.NewCipher(key[half:])
+	if err != nil {
+		return nil, err
+	}
+
+	macKey := make([]byte, half)
+	copy(macKey, key[:half])
+	return &cbcHMAC{block: block, macKey: macKey, newHash: newHash, tagSize: half}, nil
+}
+
+func (c *cbcHMAC) NonceSize() int { return cbcHMACNonceSize }
+
+// Overhead returns the maximum overhead: a full block of padding plus the tag.
+func (c *cbcHMAC) Overhead() int { return aes.BlockSize + c.tagSize }
+
+func (c *cbcHMAC) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
+	if len(nonce) != cbcHMACNonceSize {
+		panic("goaes: incorrect nonce length given to CBC-HMAC")
+	}
+
+	n := pkcs7PaddedLen(len(plaintext), aes.BlockSize)
+	ret, out := sliceForAppend(dst, n+c.tagSize)
+	ct := out[:n]
+	pkcs7Fill(ct, copy(ct, plaintext))
+	cipher.NewCBCEncrypter(c.block, nonce).CryptBlocks(ct, ct)
+
+	tag := c.tag(nonce, ct, additionalData)
+	copy(out[n:], tag)
+	return ret
+}
+
+func (c *cbcHMAC) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(nonce) != cbcHMACNonceSize {
+		panic("goaes: incorrect nonce length given to CBC-HMAC")
+	}
+	if len(ciphertext) < aes.BlockSize+c.tagSize {
+		return nil, errOpen
+	}
+
+	ctLen := len(ciphertext) - c.tagSize
+	ct := ciphertext[:ctLen]
+
+	// Verify the tag before touching the padding.
+	expected := c.tag(nonce, ct, additionalData)
+	if !hmac.Equal(expected, ciphertext[ctLen:]) {
+		return nil, errOpen
+	}
+	if ctLen%aes.BlockSize != 0 {
+		return nil, errOpen
+	}
+
+	ret, out := sliceForAppend(dst, ctLen)
+	cipher.NewCBCDecrypter(c.block, nonce).CryptBlocks(out, ct)
+	unpadded, err := pkcs7Unpad(out, aes.BlockSize)
+	if err != nil {
+		clear(out)
+		return nil, errOpen
+	}
+	return ret[:len(dst)+len(unpadded)], nil
+}
+
+// tag computes the truncated HMAC over A || IV || E || AL, where AL is the
+// AAD length in bits as a 64-bit big-endian integer.
+func (c *cbcHMAC) tag(iv, ciphertext, additionalData []byte) []byte {
+	m := hmac.New(c.newHash, c.macKey)
+	m.Write(additionalData)
+	m.Write(iv)
+	m.Write(ciphertext)
+	var al [8]byte
+	binary.BigEndian.PutUint64(al[:], uint64(len(additionalData))*8)
+	m.Write(al[:])
+	return m.Sum(nil)[:c.tagSize]
+}
@@ -0,0 +1,122 @@
+package goaes_test
+
+import (
+	"bytes"
+	"testing"
+
+	goaes "github.com/fawwazid/go-aes"
+)
+
+func TestAESCBCHMAC_EncryptDecrypt(t *testing.T) {
+	plaintext := []byte("Teams that cannot move to GCM still need integrity")
+	aad := []byte("header-aad")
+
+	for _, k := range []int{32, 48, 64} {
+		key := make([]byte, k)
+		for i := 0; i < k; i++ {
+			key[i] = byte(i)
+		}
+
+		ct, err := goaes.EncryptCBCHMAC(key, plaintext, aad)
+		if err != nil {
+			t.Fatalf("encrypt failed for key len %d: %v", k, err)
+		}
+
+		pt, err := goaes.DecryptCBCHMAC(key, ct, aad)
+		if err != nil {
+			t.Fatalf("decrypt failed for key len %d: %v", k, err)
+		}
+		if !bytes.Equal(pt, plaintext) {
+			t.Fatalf("plaintext mismatch for key len %d", k)
+		}
+
+		// tamper detection: flip a ciphertext byte, the tag, and the IV
+		for _, pos := range []int{0, 20, len(ct) - 1} {
+			bad := make([]byte, len(ct))
+			copy(bad, ct)
+			bad[pos] ^= 0xFF
+			if _, err := goaes.DecryptCBCHMAC(key, bad, aad); err == nil {
+				t.Fatalf("expected decryption error for tampered byte %d (key len %d)", pos, k)
+			}
+		}
+
+		if _, err := goaes.DecryptCBCHMAC(key, ct, nil); err == nil {
+			t.Fatalf("expected decryption error for missing aad (key len %d)", k)
+		}
+	}
+}
+
+func TestAESCBCHMAC_InvalidKey(t *testing.T) {
+	for _, k := range []int{16, 24, 33} {
+		if _, err := goaes.EncryptCBCHMAC(make([]byte, k), []byte("secret"), nil); err == nil {
+			t.Errorf("expected error for key len %d in EncryptCBCHMAC", k)
+		}
+		if _, err := goaes.NewCBCHMAC(make([]byte, k)); err == nil {
+			t.Errorf("expected error for key len %d in NewCBCHMAC", k)
+		}
+	}
+	if _, err := goaes.DecryptCBCHMAC(make([]byte, 32), []byte("short"), nil); err == nil {
+		t.Error("expected error for short ciphertext in DecryptCBCHMAC")
+	}
+}
+
+// TestAESCBCHMAC_RFC7518 checks the RFC 7518 Appendix B test cases.
+func TestAESCBCHMAC_RFC7518(t *testing.T) {
+	plaintext := []byte("A cipher system must not be required to be secret, and it must be able to fall into the hands of the enemy without inconvenience")
+	aad := []byte("The second principle of Auguste Kerckhoffs")
+	iv := mustHex(t, "1af38c2dc2b96ffdd86694092341bc04")
+
+	tests := []struct {
+		name       string
+		keyLen     int
+		ciphertext string
+		tag        string
+	}{
+		{
+			name:       "A128CBC-HS256",
+			keyLen:     32,
+			ciphertext: "c80edfa32ddf39d5ef00c0b468834279a2e46a1b8049f792f76bfe54b903a9c9a94ac9b47ad2655c5f10f9aef71427e2fc6f9b3f399a221489f16362c703233609d45ac69864e3321cf82935ac4096c86e133314c54019e8ca7980dfa4b9cf1b384c486f3a54c51078158ee5d79de59fbd34d848b3d69550a67646344427ade54b8851ffb598f7f80074b9473c82e2db",
+			tag:        "652c3fa36b0a7c5b3219fab3a30bc1c4",
+		},
+		{
+			name:       "A192CBC-HS384",
+			keyLen:     48,
+			ciphertext: "ea65da6b59e61edb419be62d19712ae5d303eeb50052d0dfd6697f77224c8edb000d279bdc14c1072654bd30944230c657bed4ca0c9f4a8466f22b226d1746214bf8cfc2400add9f5126e479663fc90b3bed787a2f0ffcbf3904be2a641d5c2105bfe591bae23b1d7449e532eef60a9ac8bb6c6b01d35d49787bcd57ef484927f280adc91ac0c4e79c7b11efc60054e3",
+			tag:        "8490ac0e58949bfe51875d733f93ac2075168039ccc733d7",
+		},
+		{
+			name:       "A256CBC-HS512",
+			keyLen:     64,
+			ciphertext: "4affaaadb78c31c5da4b1b590d10ffbd3dd8d5d302423526912da037ecbcc7bd822c301dd67c373bccb584ad3e9279c2e6d12a1374b77f077553df829410446b36ebd97066296ae6427ea75c2e0846a11a09ccf5370dc80bfecbad28c73f09b3a3b75e662a2594410ae496b2e2e6609e31e6e02cc837f053d21f37ff4f51950bbe2638d09dd7a4930930806d0703b1f6",
+			tag:        "4dd3b4c088a7f45c216839645b2012bf2e6269a8c56a816dbc1b267761955bc5",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key := make([]byte, tt.keyLen)
+			for i := range key {
+				key[i] = byte(i)
+			}
+
+			aead, err := goaes.NewCBCHMAC(key)
+			if err != nil {
+				t.Fatalf("NewCBCHMAC failed: %v", err)
+			}
+
+			want := append(mustHex(t, tt.ciphertext), mustHex(t, tt.tag)...)
+			got := aead.Seal(nil, iv, plaintext, aad)
+			if !bytes.Equal(got, want) {
+				t.Fatalf("Seal = %x, want %x", got, want)
+			}
+
+			pt, err := aead.Open(nil, iv, got, aad)
+			if err != nil {
+				t.Fatalf("Open failed: %v", err)
+			}
+			if !bytes.Equal(pt, plaintext) {
+				t.Fatalf("plaintext mismatch")
+			}
+		})
+	}
+}
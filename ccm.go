@@ -0,0 +1,216 @@
+package goaes
+
+import (
+	"crypto/cipher"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+)
+
+// EncryptCCM encrypts plaintext using AES-CCM (Counter with CBC-MAC).
+//
+// NIST SP 800-38C Recommendation: Authenticated Encryption (AEAD).
+// Common in constrained protocols such as BLE, IEEE 802.15.4 and Zigbee.
+// Short tags (4 or 6 bytes) offer weak forgery resistance; prefer 16 bytes
+// unless the peer protocol mandates otherwise.
+//
+// Parameters:
+//   - key: 16/24/32 bytes (AES-128/192/256).
+//   - plaintext: Data to be encrypted.
+//   - aad: Additional Authenticated Data (optional, can be nil).
+//   - nonceSize: 7 to 13 bytes. Larger nonces reduce the maximum message length.
+//   - tagSize: 4, 6, 8, 10, 12, 14 or 16 bytes.
+//
+// Returns: nonce||ciphertext
+func EncryptCCM(key, plaintext, aad []byte, nonceSize, tagSize int) ([]byte, error) {
+	return SealCCM(nil, key, plaintext, aad, nonceSize, tagSize)
+}
+
+// DecryptCCM decrypts data produced by EncryptCCM.
+// It expects the nonce to be prepended to the ciphertext.
+//
+// Parameters:
+//   - key: same key used for encryption.
+//   - ciphertext: nonce||ciphertext.
+//   - aad: same additional data used for encryption.
+//   - nonceSize, tagSize: same values used for encryption.
+//
+// Returns: decrypted plaintext.
+func DecryptCCM(key, ciphertext, aad []byte, nonceSize, tagSize int) ([]byte, error) {
+	return OpenCCM(nil, key, ciphertext, aad, nonceSize, tagSize)
+}
+
+// SealCCM is the append-style form of EncryptCCM: it appends
+// nonce||ciphertext to dst and returns the updated slice. dst may overlap
+// plaintext, e.g. buf[:0] to encrypt buf in place; aad must not overlap dst.
+func SealCCM(dst, key, plaintext, aad []byte, nonceSize, tagSize int) ([]byte, error) {
+	aead, err := newCCM(key, nonceSize, tagSize)
+	if err != nil {
+		return nil, err
+	}
+
+	if uint64(len(plaintext)) > aead.maxLength() {
+		return nil, errors.New("plaintext too long for CCM nonce size")
+	}
+	return sealPrefixed(dst, aead, plaintext, aad)
+}
+
+// OpenCCM is the append-style form of DecryptCCM: it appends the plaintext
+// to dst and returns the updated slice. dst may overlap ciphertext, e.g.
+// buf[:0] to decrypt buf in place.
+func OpenCCM(dst, key, ciphertext, aad []byte, nonceSize, tagSize int) ([]byte, error) {
+	aead, err := newCCM(key, nonceSize, tagSize)
+	if err != nil {
+		return nil, err
+	}
+	return openPrefixed(dst, aead, ciphertext, aad)
+}
+
+// NewCCM returns AES-CCM as a cipher.AEAD, for use wherever cipher.NewGCM
+// would be used. The caller is responsible for never reusing a nonce.
+//
+// Parameters:
+//   - key: 16/24/32 bytes (AES-128/192/256).
+//   - nonceSize: 7 to 13 bytes.
+//   - tagSize: 4, 6, 8, 10, 12, 14 or 16 bytes.
+func NewCCM(key []byte, nonceSize, tagSize int) (cipher.AEAD, error) {
+	return newCCM(key, nonceSize, tagSize)
+}
+
+// ccm implements cipher.AEAD for AES-CCM.
+type ccm struct {
+	block     cipher.Block
+	nonceSize int
+	tagSize   int
+}
+
+// newCCM validates the key and parameters and returns a CCM AEAD.
+func newCCM(key []byte, nonceSize, tagSize int) (*ccm, error) {
+	if nonceSize < 7 || nonceSize > 13 {
+		return nil, errors.New("invalid CCM nonce size: must be 7 to 13 bytes")
+	}
+	if tagSize < 4 || tagSize > 16 || tagSize%2 != 0 {
+		return nil, errors.New("invalid CCM tag size: must be an even value from 4 to 16 bytes")
+	}
+
+	block, err := newCipherBlock(key)
+	if err != nil {
+		return nil, err
+	}
+	return &ccm{block: block, nonceSize: nonceSize, tagSize: tagSize}, nil
+}
+
+func (c *ccm) NonceSize() int { return c.nonceSize }
+
+func (c *ccm) Overhead() int { return c.tagSize }
+
+// maxLength returns the largest payload the length field can encode.
+func (c *ccm) maxLength() uint64 {
+	q := 15 - c.nonceSize
+	if q >= 8 {
+		return 1<<63 - 1
+	}
+	return 1<<(8*q) - 1
+}
+
+func (c *ccm) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
+	if len(nonce) != c.nonceSize {
+		panic("goaes: incorrect nonce length given to CCM")
+	}
+	if uint64(len(plaintext)) > c.maxLength() {
+		panic("goaes: message too large for CCM")
+	}
+
+	tag := c.mac(nonce, plaintext, additionalData)
+
+	ret, out := sliceForAppend(dst, len(plaintext)+c.tagSize)
+	s0 := c.crypt(nonce, out[:len(plaintext)], plaintext)
+	subtle.XORBytes(out[len(plaintext):], tag[:c.tagSize], s0[:c.tagSize])
+	return ret
+}
+
+func (c *ccm) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(nonce) != c.nonceSize {
+		panic("goaes: incorrect nonce length given to CCM")
+	}
+	if len(ciphertext) < c.tagSize || uint64(len(ciphertext)-c.tagSize) > c.maxLength() {
+		return nil, errOpen
+	}
+
+	ctLen := len(ciphertext) - c.tagSize
+	var tag [16]byte
+	copy(tag[:], ciphertext[ctLen:])
+
+	ret, out := sliceForAppend(dst, ctLen)
+	s0 := c.crypt(nonce, out, ciphertext[:ctLen])
+	subtle.XORBytes(tag[:c.tagSize], tag[:c.tagSize], s0[:c.tagSize])
+
+	expected := c.mac(nonce, out, additionalData)
+	if subtle.ConstantTimeCompare(expected[:c.tagSize], tag[:c.tagSize]) != 1 {
+		clear(out)
+		return nil, errOpen
+	}
+	return ret, nil
+}
+
+// mac computes the CBC-MAC over the formatted B0, AAD and payload blocks
+// (SP 800-38C, Appendix A.2).
+func (c *ccm) mac(nonce, plaintext, additionalData []byte) [16]byte {
+	q := 15 - c.nonceSize
+
+	var y [16]byte
+	y[0] = byte(((c.tagSize - 2) / 2) << 3)
+	y[0] |= byte(q - 1)
+	if len(additionalData) > 0 {
+		y[0] |= 0x40
+	}
+	copy(y[1:], nonce)
+	var length [8]byte
+	binary.BigEndian.PutUint64(length[:], uint64(len(plaintext)))
+	copy(y[16-q:], length[8-q:])
+	c.block.Encrypt(y[:], y[:])
+
+	if len(additionalData) > 0 {
+		var hdr []byte
+		switch n := uint64(len(additionalData)); {
+		case n < 0xff00:
+			hdr = binary.BigEndian.AppendUint16(nil, uint16(n))
+		case n <= 0xffffffff:
+			hdr = binary.BigEndian.AppendUint32([]byte{0xff, 0xfe}, uint32(n))
+		default:
+			hdr = binary.BigEndian.AppendUint64([]byte{0xff, 0xff}, n)
+		}
+		c.cbcMAC(&y, append(hdr, additionalData...))
+	}
+	c.cbcMAC(&y, plaintext)
+	return y
+}
+
+// cbcMAC absorbs data into the running CBC-MAC state y, zero-padding the
+// final partial block.
+func (c *ccm) cbcMAC(y *[16]byte, data []byte) {
+	for len(data) > 0 {
+		n := subtle.XORBytes(y[:], y[:], data)
+		data = data[n:]
+		c.block.Encrypt(y[:], y[:])
+	}
+}
+
+// crypt applies the CCM counter keystream to src and returns S0, the
+// keystream block used to mask the tag.
+func (c *ccm) crypt(nonce, dst, src []byte) [16]byte {
+	q := 15 - c.nonceSize
+
+	var ctr [16]byte
+	ctr[0] = byte(q - 1)
+	copy(ctr[1:], nonce)
+
+	var s0 [16]byte
+	c.block.Encrypt(s0[:], ctr[:])
+
+	// The counter occupies the low q bytes and cannot overflow for payloads
+	// within maxLength, so the standard 128-bit big-endian CTR applies.
+	ctr[15] = 1
+	cipher.NewCTR(c.block, ctr[:]).XORKeyStream(dst, src)
+	return s0
+}
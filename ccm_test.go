@@ -0,0 +1,130 @@
+package goaes_test
+
+import (
+	"bytes"
+	"testing"
+
+	goaes "github.com/fawwazid/go-aes"
+)
+
+func TestAESCCM_EncryptDecrypt(t *testing.T) {
+	plaintext := []byte("Jackdaws love my big sphinx of quartz")
+	aad := []byte("header-aad")
+
+	for _, k := range []int{16, 24, 32} {
+		key := make([]byte, k)
+		for i := 0; i < k; i++ {
+			key[i] = byte(i)
+		}
+
+		for _, p := range []struct{ nonce, tag int }{{7, 4}, {13, 4}, {12, 16}, {13, 8}} {
+			ct, err := goaes.EncryptCCM(key, plaintext, aad, p.nonce, p.tag)
+			if err != nil {
+				t.Fatalf("encrypt failed for key len %d (%+v): %v", k, p, err)
+			}
+			if len(ct) != p.nonce+len(plaintext)+p.tag {
+				t.Fatalf("unexpected ciphertext length %d for key len %d (%+v)", len(ct), k, p)
+			}
+
+			pt, err := goaes.DecryptCCM(key, ct, aad, p.nonce, p.tag)
+			if err != nil {
+				t.Fatalf("decrypt failed for key len %d (%+v): %v", k, p, err)
+			}
+
+			if !bytes.Equal(pt, plaintext) {
+				t.Fatalf("plaintext mismatch for key len %d (%+v)", k, p)
+			}
+
+			// tamper detection: flip last byte
+			bad := make([]byte, len(ct))
+			copy(bad, ct)
+			bad[len(bad)-1] ^= 0xFF
+			_, err = goaes.DecryptCCM(key, bad, aad, p.nonce, p.tag)
+			if err == nil {
+				t.Fatalf("expected decryption error for tampered ciphertext (key len %d, %+v)", k, p)
+			}
+		}
+	}
+}
+
+func TestAESCCM_InvalidParams(t *testing.T) {
+	key := make([]byte, 16)
+
+	_, err := goaes.EncryptCCM([]byte("invalid-key"), []byte("secret"), nil, 13, 16)
+	if err == nil {
+		t.Error("expected error for invalid key size in EncryptCCM")
+	}
+
+	for _, n := range []int{0, 6, 14, 16} {
+		if _, err := goaes.NewCCM(key, n, 16); err == nil {
+			t.Errorf("expected error for nonce size %d", n)
+		}
+	}
+	for _, tag := range []int{0, 2, 5, 15, 18} {
+		if _, err := goaes.NewCCM(key, 13, tag); err == nil {
+			t.Errorf("expected error for tag size %d", tag)
+		}
+	}
+
+	_, err = goaes.DecryptCCM(key, []byte("short"), nil, 13, 16)
+	if err == nil {
+		t.Error("expected error for short ciphertext in DecryptCCM")
+	}
+}
+
+// TestAESCCM_SP80038C checks the examples from NIST SP 800-38C Appendix C.
+func TestAESCCM_SP80038C(t *testing.T) {
+	key := mustHex(t, "404142434445464748494a4b4c4d4e4f")
+	tests := []struct {
+		name, nonce, aad, plaintext, ciphertext string
+		tagSize                                 int
+	}{
+		{
+			name:       "Example 1",
+			nonce:      "10111213141516",
+			aad:        "0001020304050607",
+			plaintext:  "20212223",
+			ciphertext: "7162015b4dac255d",
+			tagSize:    4,
+		},
+		{
+			name:       "Example 2",
+			nonce:      "1011121314151617",
+			aad:        "000102030405060708090a0b0c0d0e0f",
+			plaintext:  "202122232425262728292a2b2c2d2e2f",
+			ciphertext: "d2a1f0e051ea5f62081a7792073d593d1fc64fbfaccd",
+			tagSize:    6,
+		},
+		{
+			name:       "Example 3",
+			nonce:      "101112131415161718191a1b",
+			aad:        "000102030405060708090a0b0c0d0e0f10111213",
+			plaintext:  "202122232425262728292a2b2c2d2e2f3031323334353637",
+			ciphertext: "e3b201a9f5b71a7a9b1ceaeccd97e70b6176aad9a4428aa5484392fbc1b09951",
+			tagSize:    8,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nonce := mustHex(t, tt.nonce)
+			aead, err := goaes.NewCCM(key, len(nonce), tt.tagSize)
+			if err != nil {
+				t.Fatalf("NewCCM failed: %v", err)
+			}
+
+			ct := aead.Seal(nil, nonce, mustHex(t, tt.plaintext), mustHex(t, tt.aad))
+			if !bytes.Equal(ct, mustHex(t, tt.ciphertext)) {
+				t.Fatalf("ciphertext = %x, want %s", ct, tt.ciphertext)
+			}
+
+			pt, err := aead.Open(nil, nonce, ct, mustHex(t, tt.aad))
+			if err != nil {
+				t.Fatalf("open failed: %v", err)
+			}
+			if !bytes.Equal(pt, mustHex(t, tt.plaintext)) {
+				t.Fatalf("plaintext = %x, want %s", pt, tt.plaintext)
+			}
+		})
+	}
+}
@@ -2,7 +2,6 @@ package goaes
 
 import (
 	"crypto/cipher"
-	"crypto/rand"
 	"errors"
 	"io"
 )
@@ -20,52 +19,234 @@ import (
 //
 // Returns: IV prepended to ciphertext (iv||ciphertext).
 func EncryptCFB(key, plaintext []byte) ([]byte, error) {
-	block, err := newCipherBlock(key)
-	if err != nil {
-		return nil, err
-	}
+	return SealCFB(nil, key, plaintext)
+}
 
-	bs := block.BlockSize()
-	iv := make([]byte, bs)
-	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
-		return nil, err
-	}
+// DecryptCFB decrypts data produced by EncryptCFB.
+// It expects the IV to be prepended to the ciphertext.
+//
+// Parameters:
+//   - key: same key used for encryption.
+//   - ciphertext: iv||ciphertext.
+//
+// Returns: decrypted plaintext.
+func DecryptCFB(key, ciphertext []byte) ([]byte, error) {
+	return OpenCFB(nil, key, ciphertext)
+}
 
-	ct := make([]byte, len(plaintext))
-	stream := cipher.NewCFBEncrypter(block, iv)
-	stream.XORKeyStream(ct, plaintext)
+// SealCFB is the append-style form of EncryptCFB: it appends iv||ciphertext
+// to dst and returns the updated slice. dst may overlap plaintext, e.g.
+// buf[:0] to encrypt buf in place.
+func SealCFB(dst, key, plaintext []byte) ([]byte, error) {
+	return sealStream(dst, key, NewCFBEncrypter, plaintext)
+}
 
-	out := make([]byte, 0, len(iv)+len(ct))
-	out = append(out, iv...)
-	out = append(out, ct...)
-	return out, nil
+// OpenCFB is the append-style form of DecryptCFB: it appends the plaintext
+// to dst and returns the updated slice. dst may overlap ciphertext, e.g.
+// buf[:0] to decrypt buf in place.
+func OpenCFB(dst, key, ciphertext []byte) ([]byte, error) {
+	return openStream(dst, key, NewCFBDecrypter, ciphertext)
 }
 
-// DecryptCFB decrypts data produced by EncryptCFB.
+// NewCFBEncrypter returns AES in CFB128 mode as an encrypting cipher.Stream,
+// for use wherever cipher.NewCFBEncrypter would be used.
+//
+// NIST SP 800-38A Warning: The IV must be unpredictable (random) for every
+// message. No integrity is provided.
+//
+// Parameters:
+//   - key: 16, 24, or 32 bytes (AES-128, 192, or 256).
+//   - iv: 16 bytes. It is copied.
+func NewCFBEncrypter(key, iv []byte) (cipher.Stream, error) {
+	return newCFB(key, iv, 128, false)
+}
+
+// NewCFBDecrypter returns AES in CFB128 mode as a decrypting cipher.Stream.
+//
+// Parameters:
+//   - key: same key used for encryption.
+//   - iv: same IV used for encryption. It is copied.
+func NewCFBDecrypter(key, iv []byte) (cipher.Stream, error) {
+	return newCFB(key, iv, 128, true)
+}
+
+// EncryptCFBWithSegmentSize encrypts plaintext using AES in CFB mode with the
+// given segment size, as defined in NIST SP 800-38A (CFB1, CFB8 or CFB128).
+//
+// NIST SP 800-38A Warning: This mode provides Confidentiality ONLY.
+// CFB1 and CFB8 need one AES operation per bit or byte respectively, so they
+// are much slower than CFB128; use them only for interoperability.
+//
+// Recommendation: Use EncryptGCM (AEAD) instead.
+//
+// Parameters:
+//   - key: 16, 24, or 32 bytes (AES-128, 192, or 256).
+//   - plaintext: Data to be encrypted.
+//   - segmentBits: 1, 8, or 128. 128 is equivalent to EncryptCFB.
+//
+// Returns: IV prepended to ciphertext (iv||ciphertext).
+func EncryptCFBWithSegmentSize(key, plaintext []byte, segmentBits int) ([]byte, error) {
+	return SealCFBWithSegmentSize(nil, key, plaintext, segmentBits)
+}
+
+// DecryptCFBWithSegmentSize decrypts data produced by EncryptCFBWithSegmentSize.
 // It expects the IV to be prepended to the ciphertext.
 //
 // Parameters:
 //   - key: same key used for encryption.
 //   - ciphertext: iv||ciphertext.
+//   - segmentBits: same segment size used for encryption.
 //
 // Returns: decrypted plaintext.
-func DecryptCFB(key, ciphertext []byte) ([]byte, error) {
+func DecryptCFBWithSegmentSize(key, ciphertext []byte, segmentBits int) ([]byte, error) {
+	return OpenCFBWithSegmentSize(nil, key, ciphertext, segmentBits)
+}
+
+// SealCFBWithSegmentSize is the append-style form of
+// EncryptCFBWithSegmentSize: it appends iv||ciphertext to dst and returns the
+// updated slice. dst may overlap plaintext, e.g. buf[:0] to encrypt buf in
+// place.
+func SealCFBWithSegmentSize(dst, key, plaintext []byte, segmentBits int) ([]byte, error) {
+	if err := validateCFBSegmentSize(segmentBits); err != nil {
+		return nil, err
+	}
+
+	return sealStream(dst, key, func(key, iv []byte) (cipher.Stream, error) {
+		return newCFB(key, iv, segmentBits, false)
+	}, plaintext)
+}
+
+// OpenCFBWithSegmentSize is the append-style form of
+// DecryptCFBWithSegmentSize: it appends the plaintext to dst and returns the
+// updated slice. dst may overlap ciphertext, e.g. buf[:0] to decrypt buf in
+// place.
+func OpenCFBWithSegmentSize(dst, key, ciphertext []byte, segmentBits int) ([]byte, error) {
+	if err := validateCFBSegmentSize(segmentBits); err != nil {
+		return nil, err
+	}
+
+	return openStream(dst, key, func(key, iv []byte) (cipher.Stream, error) {
+		return newCFB(key, iv, segmentBits, true)
+	}, ciphertext)
+}
+
+// validateCFBSegmentSize checks that segmentBits is 1, 8, or 128.
+func validateCFBSegmentSize(segmentBits int) error {
+	if segmentBits != 1 && segmentBits != 8 && segmentBits != 128 {
+		return errors.New("invalid CFB segment size: must be 1, 8, or 128 bits")
+	}
+	return nil
+}
+
+// newCFB validates the key and IV and returns a CFB stream with the given
+// (already validated) segment size.
+func newCFB(key, iv []byte, segmentBits int, decrypt bool) (cipher.Stream, error) {
 	block, err := newCipherBlock(key)
 	if err != nil {
 		return nil, err
 	}
+	if err := validateIVSize(iv); err != nil {
+		return nil, err
+	}
+	return newCFBSegmentStream(block, iv, segmentBits, decrypt), nil
+}
+
+// newCFBSegmentStream returns a cipher.Stream for CFB with the given
+// (already validated) segment size.
+func newCFBSegmentStream(block cipher.Block, iv []byte, segmentBits int, decrypt bool) cipher.Stream {
+	switch segmentBits {
+	case 1:
+		return newCFBShift(block, iv, true, decrypt)
+	case 8:
+		return newCFBShift(block, iv, false, decrypt)
+	default:
+		if decrypt {
+			return cipher.NewCFBDecrypter(block, iv)
+		}
+		return cipher.NewCFBEncrypter(block, iv)
+	}
+}
+
+// cfbShift implements CFB1 and CFB8 using a 128-bit shift register that is
+// fed back with each ciphertext segment.
+type cfbShift struct {
+	block   cipher.Block
+	reg     [16]byte
+	out     [16]byte
+	bitMode bool
+	decrypt bool
+}
 
-	bs := block.BlockSize()
-	if len(ciphertext) < bs {
-		return nil, errors.New("ciphertext too short")
+func newCFBShift(block cipher.Block, iv []byte, bitMode, decrypt bool) *cfbShift {
+	s := &cfbShift{block: block, bitMode: bitMode, decrypt: decrypt}
+	copy(s.reg[:], iv)
+	return s
+}
+
+func (s *cfbShift) XORKeyStream(dst, src []byte) {
+	if len(dst) < len(src) {
+		panic("goaes: output smaller than input")
 	}
+	for i, in := range src {
+		if !s.bitMode {
+			s.block.Encrypt(s.out[:], s.reg[:])
+			c := in ^ s.out[0]
+			dst[i] = c
+			if s.decrypt {
+				c = in
+			}
+			copy(s.reg[:], s.reg[1:])
+			s.reg[15] = c
+			continue
+		}
 
-	iv := ciphertext[:bs]
-	ct := ciphertext[bs:]
+		var res byte
+		for bit := 7; bit >= 0; bit-- {
+			s.block.Encrypt(s.out[:], s.reg[:])
+			p := (in >> bit) & 1
+			c := p ^ (s.out[0] >> 7)
+			res |= c << bit
+			if s.decrypt {
+				c = p
+			}
+			s.shiftInBit(c)
+		}
+		dst[i] = res
+	}
+}
 
-	pt := make([]byte, len(ct))
-	stream := cipher.NewCFBDecrypter(block, iv)
-	stream.XORKeyStream(pt, ct)
+// shiftInBit shifts the register left by one bit and appends b.
+func (s *cfbShift) shiftInBit(b byte) {
+	for j := 0; j < 15; j++ {
+		s.reg[j] = s.reg[j]<<1 | s.reg[j+1]>>7
+	}
+	s.reg[15] = s.reg[15]<<1 | b
+}
 
-	return pt, nil
+// NewCFBWriter returns a writer that encrypts data written to it into w with
+// AES-CFB (128-bit segments), for data too large to hold in memory. A random
+// IV is written to w first, so the complete output is byte-compatible with
+// EncryptCFB and can be decrypted with DecryptCFB or NewCFBReader.
+//
+// NIST SP 800-38A Warning: This mode provides Confidentiality ONLY.
+//
+// Parameters:
+//   - w: destination for iv||ciphertext.
+//   - key: 16, 24, or 32 bytes (AES-128, 192, or 256).
+//
+// Returns an error if the IV cannot be generated or written.
+func NewCFBWriter(w io.Writer, key []byte) (io.Writer, error) {
+	return newStreamWriter(w, key, NewCFBEncrypter)
+}
+
+// NewCFBReader returns a reader that decrypts iv||ciphertext from r, as
+// produced by EncryptCFB or NewCFBWriter. The IV is read immediately.
+//
+// Parameters:
+//   - r: source of iv||ciphertext.
+//   - key: same key used for encryption.
+//
+// Returns an error if the IV cannot be read.
+func NewCFBReader(r io.Reader, key []byte) (io.Reader, error) {
+	return newStreamReader(r, key, NewCFBDecrypter)
 }
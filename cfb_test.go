@@ -3,6 +3,7 @@ package goaes_test
 import (
 	"bytes"
 	"testing"
+	"testing/iotest"
 
 	goaes "github.com/fawwazid/go-aes"
 )
@@ -44,3 +45,154 @@ func TestAESCFB_EncryptDecrypt(t *testing.T) {
 		}
 	}
 }
+
+func TestAESCFB_SegmentSizes(t *testing.T) {
+	plaintext := []byte("Serial-line peers speak CFB8; legacy ones CFB1.")
+
+	for _, k := range []int{16, 24, 32} {
+		key := make([]byte, k)
+		for i := 0; i < k; i++ {
+			key[i] = byte(i)
+		}
+
+		for _, bits := range []int{1, 8, 128} {
+			ct, err := goaes.EncryptCFBWithSegmentSize(key, plaintext, bits)
+			if err != nil {
+				t.Fatalf("encrypt failed for key len %d, CFB%d: %v", k, bits, err)
+			}
+			if len(ct) != 16+len(plaintext) {
+				t.Fatalf("ciphertext len = %d, want %d", len(ct), 16+len(plaintext))
+			}
+
+			pt, err := goaes.DecryptCFBWithSegmentSize(key, ct, bits)
+			if err != nil {
+				t.Fatalf("decrypt failed for key len %d, CFB%d: %v", k, bits, err)
+			}
+			if !bytes.Equal(pt, plaintext) {
+				t.Fatalf("plaintext mismatch for key len %d, CFB%d", k, bits)
+			}
+		}
+
+		// CFB128 output must be readable by DecryptCFB.
+		ct, err := goaes.EncryptCFBWithSegmentSize(key, plaintext, 128)
+		if err != nil {
+			t.Fatalf("encrypt failed for key len %d: %v", k, err)
+		}
+		pt, err := goaes.DecryptCFB(key, ct)
+		if err != nil || !bytes.Equal(pt, plaintext) {
+			t.Fatalf("CFB128 not compatible with DecryptCFB for key len %d", k)
+		}
+	}
+
+	if _, err := goaes.EncryptCFBWithSegmentSize(make([]byte, 16), plaintext, 64); err == nil {
+		t.Error("expected error for unsupported segment size")
+	}
+	if _, err := goaes.DecryptCFBWithSegmentSize(make([]byte, 16), make([]byte, 8), 8); err == nil {
+		t.Error("expected error for short ciphertext")
+	}
+}
+
+// TestAESCFB_SP80038A checks the SP 800-38A Appendix F.3 CFB1 and CFB8 vectors.
+func TestAESCFB_SP80038A(t *testing.T) {
+	iv := mustHex(t, "000102030405060708090a0b0c0d0e0f")
+	tests := []struct {
+		name, key, plaintext, ciphertext string
+		bits                             int
+	}{
+		{"CFB1-AES128", "2b7e151628aed2a6abf7158809cf4f3c", "6bc1", "68b3", 1},
+		{"CFB1-AES192", "8e73b0f7da0e6452c810f32b809079e562f8ead2522c6b7b", "6bc1", "9359", 1},
+		{"CFB1-AES256", "603deb1015ca71be2b73aef0857d77811f352c073b6108d72d9810a30914dff4", "6bc1", "9029", 1},
+		{"CFB8-AES128", "2b7e151628aed2a6abf7158809cf4f3c", "6bc1bee22e409f96e93d7e117393172aae2d", "3b79424c9c0dd436bace9e0ed4586a4f32b9", 8},
+		{"CFB8-AES192", "8e73b0f7da0e6452c810f32b809079e562f8ead2522c6b7b", "6bc1bee22e409f96e93d7e117393172aae2d", "cda2521ef0a905ca44cd057cbf0d47a0678a", 8},
+		{"CFB8-AES256", "603deb1015ca71be2b73aef0857d77811f352c073b6108d72d9810a30914dff4", "6bc1bee22e409f96e93d7e117393172aae2d", "dc1f1a8520a64db55fcc8ac554844e889700", 8},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := append(append([]byte{}, iv...), mustHex(t, tt.ciphertext)...)
+			pt, err := goaes.DecryptCFBWithSegmentSize(mustHex(t, tt.key), in, tt.bits)
+			if err != nil {
+				t.Fatalf("decrypt failed: %v", err)
+			}
+			if !bytes.Equal(pt, mustHex(t, tt.plaintext)) {
+				t.Fatalf("plaintext = %x, want %s", pt, tt.plaintext)
+			}
+		})
+	}
+}
+
+func TestAESCFB_NewCFB(t *testing.T) {
+	key := bytes.Repeat([]byte{0x03}, 32)
+	iv := bytes.Repeat([]byte{0x04}, 16)
+	plaintext := []byte("Sphinx of black quartz, judge my vow")
+
+	enc, err := goaes.NewCFBEncrypter(key, iv)
+	if err != nil {
+		t.Fatalf("NewCFBEncrypter failed: %v", err)
+	}
+	ct := make([]byte, len(plaintext))
+	enc.XORKeyStream(ct[:9], plaintext[:9])
+	enc.XORKeyStream(ct[9:], plaintext[9:])
+
+	// iv||ct is the EncryptCFB format.
+	pt, err := goaes.DecryptCFB(key, append(append([]byte{}, iv...), ct...))
+	if err != nil || !bytes.Equal(pt, plaintext) {
+		t.Fatalf("DecryptCFB of stream output = %q, %v", pt, err)
+	}
+
+	dec, err := goaes.NewCFBDecrypter(key, iv)
+	if err != nil {
+		t.Fatalf("NewCFBDecrypter failed: %v", err)
+	}
+	pt = make([]byte, len(ct))
+	dec.XORKeyStream(pt, ct)
+	if !bytes.Equal(pt, plaintext) {
+		t.Fatalf("plaintext mismatch: got %q", pt)
+	}
+
+	if _, err := goaes.NewCFBDecrypter(key, iv[:15]); err == nil {
+		t.Fatal("expected error for 15-byte IV")
+	}
+}
+
+func TestAESCFB_StreamWriterReader(t *testing.T) {
+	key := bytes.Repeat([]byte{0x33}, 32)
+	plaintext := bytes.Repeat([]byte("cipher feedback "), 5000)
+
+	// Written in uneven pieces, the output is the CFB one-shot format.
+	var buf bytes.Buffer
+	w, err := goaes.NewCFBWriter(&buf, key)
+	if err != nil {
+		t.Fatalf("NewCFBWriter failed: %v", err)
+	}
+	for p := plaintext; len(p) > 0; {
+		n := min(len(p), 1337)
+		if _, err := w.Write(p[:n]); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+		p = p[n:]
+	}
+	pt, err := goaes.DecryptCFB(key, buf.Bytes())
+	if err != nil || !bytes.Equal(pt, plaintext) {
+		t.Fatalf("DecryptCFB of writer output failed: %v", err)
+	}
+
+	ct, err := goaes.EncryptCFB(key, plaintext)
+	if err != nil {
+		t.Fatalf("encrypt failed: %v", err)
+	}
+	r, err := goaes.NewCFBReader(iotest.OneByteReader(bytes.NewReader(ct)), key)
+	if err != nil {
+		t.Fatalf("NewCFBReader failed: %v", err)
+	}
+	if err := iotest.TestReader(r, plaintext); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := goaes.NewCFBReader(bytes.NewReader(ct[:10]), key); err == nil {
+		t.Fatal("expected error for truncated IV")
+	}
+	if _, err := goaes.NewCFBWriter(&buf, key[:10]); err == nil {
+		t.Fatal("expected error for invalid key size")
+	}
+}
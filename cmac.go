@@ -0,0 +1,170 @@
+package goaes
+
+import (
+	"crypto/cipher"
+	"crypto/subtle"
+	"errors"
+	"hash"
+)
+
+// cmacSize is the full CMAC tag length in bytes.
+const cmacSize = 16
+
+// NewCMAC returns a new hash.Hash computing AES-CMAC.
+//
+// NIST SP 800-38B Recommendation: Message authentication only (no encryption).
+// Use it to add integrity to the confidentiality-only modes (CBC, CFB, CTR, OFB),
+// always with a key that is independent of the encryption key.
+//
+// Parameters:
+//   - key: 16, 24, or 32 bytes (AES-128, 192, or 256).
+//
+// Returns: hash.Hash whose Sum appends the 16-byte tag.
+func NewCMAC(key []byte) (hash.Hash, error) {
+	block, err := newCipherBlock(key)
+	if err != nil {
+		return nil, err
+	}
+	return &cmac{key: newCMACKey(block)}, nil
+}
+
+// CMAC returns the 16-byte AES-CMAC tag of msg.
+//
+// Parameters:
+//   - key: 16, 24, or 32 bytes (AES-128, 192, or 256).
+//   - msg: Data to be authenticated.
+//
+// Returns: tag.
+func CMAC(key, msg []byte) ([]byte, error) {
+	block, err := newCipherBlock(key)
+	if err != nil {
+		return nil, err
+	}
+	tag := newCMACKey(block).sum(msg)
+	return tag[:], nil
+}
+
+// VerifyCMAC checks in constant time that tag is the AES-CMAC of msg.
+// Tags truncated to between 8 and 16 bytes are accepted, as allowed by
+// SP 800-38B; the leftmost bytes of the full tag are compared.
+//
+// Parameters:
+//   - key: same key used to compute the tag.
+//   - msg: Data that was authenticated.
+//   - tag: tag to check.
+//
+// Returns: nil if the tag is valid.
+func VerifyCMAC(key, msg, tag []byte) error {
+	if len(tag) < 8 || len(tag) > cmacSize {
+		return errors.New("invalid CMAC tag size: must be 8 to 16 bytes")
+	}
+
+	expected, err := CMAC(key, msg)
+	if err != nil {
+		return err
+	}
+	if subtle.ConstantTimeCompare(expected[:len(tag)], tag) != 1 {
+		return errOpen
+	}
+	return nil
+}
+
+// cmac implements hash.Hash for AES-CMAC. The last block is held back in buf
+// until Sum, since it is processed differently from the others.
+type cmac struct {
+	key *cmacKey
+	x   [16]byte
+	buf [16]byte
+	n   int
+}
+
+func (c *cmac) Write(p []byte) (int, error) {
+	written := len(p)
+	for len(p) > 0 {
+		if c.n == len(c.buf) {
+			subtle.XORBytes(c.x[:], c.x[:], c.buf[:])
+			c.key.block.Encrypt(c.x[:], c.x[:])
+			c.n = 0
+		}
+		m := copy(c.buf[c.n:], p)
+		c.n += m
+		p = p[m:]
+	}
+	return written, nil
+}
+
+func (c *cmac) Sum(b []byte) []byte {
+	x := c.x
+	c.key.final(&x, c.buf[:c.n])
+	return append(b, x[:]...)
+}
+
+func (c *cmac) Reset() {
+	c.x = [16]byte{}
+	c.n = 0
+}
+
+func (c *cmac) Size() int { return cmacSize }
+
+func (c *cmac) BlockSize() int { return len(c.buf) }
+
+// cmacKey holds an AES block cipher and its derived CMAC subkeys
+// (NIST SP 800-38B, Section 6.1).
+type cmacKey struct {
+	block  cipher.Block
+	k1, k2 [16]byte
+}
+
+// newCMACKey derives the CMAC subkeys K1 and K2 for block.
+func newCMACKey(block cipher.Block) *cmacKey {
+	var l [16]byte
+	block.Encrypt(l[:], l[:])
+
+	k := &cmacKey{block: block}
+	k.k1 = gfDouble(&l)
+	k.k2 = gfDouble(&k.k1)
+	return k
+}
+
+// sum returns the full 16-byte CMAC of msg.
+func (k *cmacKey) sum(msg []byte) [16]byte {
+	return k.sumFrom([16]byte{}, msg)
+}
+
+// sumFrom continues a CMAC computation from chaining value x, treating msg
+// as the remaining input, and returns the tag.
+func (k *cmacKey) sumFrom(x [16]byte, msg []byte) [16]byte {
+	for len(msg) > 16 {
+		subtle.XORBytes(x[:], x[:], msg[:16])
+		k.block.Encrypt(x[:], x[:])
+		msg = msg[16:]
+	}
+	k.final(&x, msg)
+	return x
+}
+
+// final absorbs the last (possibly partial or empty) block into the chaining
+// value x and produces the tag in place.
+func (k *cmacKey) final(x *[16]byte, last []byte) {
+	if len(last) == 16 {
+		subtle.XORBytes(x[:], x[:], last)
+		subtle.XORBytes(x[:], x[:], k.k1[:])
+	} else {
+		subtle.XORBytes(x[:], x[:], last)
+		x[len(last)] ^= 0x80
+		subtle.XORBytes(x[:], x[:], k.k2[:])
+	}
+	k.block.Encrypt(x[:], x[:])
+}
+
+// gfDouble multiplies b by x in GF(2^128) using the big-endian convention of
+// SP 800-38B (the "dbl" operation of RFC 5297).
+func gfDouble(b *[16]byte) [16]byte {
+	var out [16]byte
+	carry := b[0] >> 7
+	for i := 0; i < 15; i++ {
+		out[i] = b[i]<<1 | b[i+1]>>7
+	}
+	out[15] = b[15]<<1 ^ (0x87 & -carry)
+	return out
+}
@@ -0,0 +1,134 @@
+package goaes_test
+
+import (
+	"bytes"
+	"testing"
+
+	goaes "github.com/fawwazid/go-aes"
+)
+
+// TestCMAC_Vectors checks the RFC 4493 Section 4 and SP 800-38B Appendix D examples.
+func TestCMAC_Vectors(t *testing.T) {
+	msg := mustHex(t, "6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e5130c81c46a35ce411e5fbc1191a0a52eff69f2445df4f9b17ad2b417be66c3710")
+
+	tests := []struct {
+		key  string
+		tags [4]string // for message lengths 0, 16, 40 and 64
+	}{
+		{
+			key: "2b7e151628aed2a6abf7158809cf4f3c",
+			tags: [4]string{
+				"bb1d6929e95937287fa37d129b756746",
+				"070a16b46b4d4144f79bdd9dd04a287c",
+				"dfa66747de9ae63030ca32611497c827",
+				"51f0bebf7e3b9d92fc49741779363cfe",
+			},
+		},
+		{
+			key: "8e73b0f7da0e6452c810f32b809079e562f8ead2522c6b7b",
+			tags: [4]string{
+				"d17ddf46adaacde531cac483de7a9367",
+				"9e99a7bf31e710900662f65e617c5184",
+				"8a1de5be2eb31aad089a82e6ee908b0e",
+				"a1d5df0eed790f794d77589659f39a11",
+			},
+		},
+		{
+			key: "603deb1015ca71be2b73aef0857d77811f352c073b6108d72d9810a30914dff4",
+			tags: [4]string{
+				"028962f61b7bf89efc6b551f4667d983",
+				"28a7023f452e8f82bd4bf28d8c37c35c",
+				"aaf3d8f1de5640c232f5b169b9c911e6",
+				"e1992190549f6ed5696a2c056c315410",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		key := mustHex(t, tt.key)
+		for i, n := range []int{0, 16, 40, 64} {
+			want := mustHex(t, tt.tags[i])
+
+			tag, err := goaes.CMAC(key, msg[:n])
+			if err != nil {
+				t.Fatalf("CMAC failed for key len %d: %v", len(key), err)
+			}
+			if !bytes.Equal(tag, want) {
+				t.Fatalf("CMAC(key len %d, msg len %d) = %x, want %x", len(key), n, tag, want)
+			}
+
+			// streaming: feed the message in uneven pieces
+			h, err := goaes.NewCMAC(key)
+			if err != nil {
+				t.Fatalf("NewCMAC failed for key len %d: %v", len(key), err)
+			}
+			for off := 0; off < n; off += 7 {
+				h.Write(msg[off:min(off+7, n)])
+			}
+			if got := h.Sum(nil); !bytes.Equal(got, want) {
+				t.Fatalf("streaming CMAC(key len %d, msg len %d) = %x, want %x", len(key), n, got, want)
+			}
+
+			if err := goaes.VerifyCMAC(key, msg[:n], want); err != nil {
+				t.Fatalf("VerifyCMAC failed for key len %d, msg len %d: %v", len(key), n, err)
+			}
+			if err := goaes.VerifyCMAC(key, msg[:n], want[:8]); err != nil {
+				t.Fatalf("VerifyCMAC (truncated) failed for key len %d, msg len %d: %v", len(key), n, err)
+			}
+		}
+	}
+}
+
+func TestCMAC_HashInterface(t *testing.T) {
+	key := make([]byte, 32)
+	h, err := goaes.NewCMAC(key)
+	if err != nil {
+		t.Fatalf("NewCMAC failed: %v", err)
+	}
+	if h.Size() != 16 || h.BlockSize() != 16 {
+		t.Fatalf("unexpected Size/BlockSize: %d/%d", h.Size(), h.BlockSize())
+	}
+
+	h.Write([]byte("hello "))
+	first := h.Sum(nil)
+	// Sum must not change the state
+	if again := h.Sum(nil); !bytes.Equal(first, again) {
+		t.Fatal("Sum changed the hash state")
+	}
+	h.Write([]byte("world"))
+	full, _ := goaes.CMAC(key, []byte("hello world"))
+	if got := h.Sum(nil); !bytes.Equal(got, full) {
+		t.Fatalf("incremental Sum = %x, want %x", got, full)
+	}
+
+	h.Reset()
+	empty, _ := goaes.CMAC(key, nil)
+	if got := h.Sum(nil); !bytes.Equal(got, empty) {
+		t.Fatalf("Sum after Reset = %x, want %x", got, empty)
+	}
+}
+
+func TestCMAC_Verify(t *testing.T) {
+	key := make([]byte, 16)
+	msg := []byte("authenticated header")
+	tag, err := goaes.CMAC(key, msg)
+	if err != nil {
+		t.Fatalf("CMAC failed: %v", err)
+	}
+
+	bad := make([]byte, len(tag))
+	copy(bad, tag)
+	bad[len(bad)-1] ^= 0xFF
+	if err := goaes.VerifyCMAC(key, msg, bad); err == nil {
+		t.Fatal("expected error for tampered tag")
+	}
+	if err := goaes.VerifyCMAC(key, []byte("other"), tag); err == nil {
+		t.Fatal("expected error for different message")
+	}
+	if err := goaes.VerifyCMAC(key, msg, tag[:4]); err == nil {
+		t.Fatal("expected error for tag shorter than 8 bytes")
+	}
+	if _, err := goaes.NewCMAC([]byte("invalid-key")); err == nil {
+		t.Fatal("expected error for invalid key size in NewCMAC")
+	}
+}
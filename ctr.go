@@ -2,8 +2,6 @@ package goaes
 
 import (
 	"crypto/cipher"
-	"crypto/rand"
-	"errors"
 	"io"
 )
 
@@ -21,25 +19,7 @@ import (
 //
 // Returns: IV prepended to ciphertext (iv||ciphertext).
 func EncryptCTR(key, plaintext []byte) ([]byte, error) {
-	block, err := newCipherBlock(key)
-	if err != nil {
-		return nil, err
-	}
-
-	bs := block.BlockSize()
-	iv := make([]byte, bs)
-	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
-		return nil, err
-	}
-
-	ct := make([]byte, len(plaintext))
-	stream := cipher.NewCTR(block, iv)
-	stream.XORKeyStream(ct, plaintext)
-
-	out := make([]byte, 0, len(iv)+len(ct))
-	out = append(out, iv...)
-	out = append(out, ct...)
-	return out, nil
+	return SealCTR(nil, key, plaintext)
 }
 
 // DecryptCTR decrypts data produced by EncryptCTR.
@@ -51,22 +31,67 @@ func EncryptCTR(key, plaintext []byte) ([]byte, error) {
 //
 // Returns: decrypted plaintext.
 func DecryptCTR(key, ciphertext []byte) ([]byte, error) {
+	return OpenCTR(nil, key, ciphertext)
+}
+
+// SealCTR is the append-style form of EncryptCTR: it appends iv||ciphertext
+// to dst and returns the updated slice. dst may overlap plaintext, e.g.
+// buf[:0] to encrypt buf in place.
+func SealCTR(dst, key, plaintext []byte) ([]byte, error) {
+	return sealStream(dst, key, NewCTR, plaintext)
+}
+
+// OpenCTR is the append-style form of DecryptCTR: it appends the plaintext
+// to dst and returns the updated slice. dst may overlap ciphertext, e.g.
+// buf[:0] to decrypt buf in place.
+func OpenCTR(dst, key, ciphertext []byte) ([]byte, error) {
+	return openStream(dst, key, NewCTR, ciphertext)
+}
+
+// NewCTR returns AES in CTR mode as a cipher.Stream, for use wherever
+// cipher.NewCTR would be used. The same stream encrypts and decrypts.
+//
+// NIST SP 800-38A Warning: Counter blocks must be unique across all messages
+// under one key; NEVER reuse a (Key, IV) pair. No integrity is provided.
+//
+// Parameters:
+//   - key: 16, 24, or 32 bytes (AES-128, 192, or 256).
+//   - iv: 16-byte initial counter block. It is copied.
+func NewCTR(key, iv []byte) (cipher.Stream, error) {
 	block, err := newCipherBlock(key)
 	if err != nil {
 		return nil, err
 	}
-
-	bs := block.BlockSize()
-	if len(ciphertext) < bs {
-		return nil, errors.New("ciphertext too short")
+	if err := validateIVSize(iv); err != nil {
+		return nil, err
 	}
+	return cipher.NewCTR(block, iv), nil
+}
 
-	iv := ciphertext[:bs]
-	ct := ciphertext[bs:]
-
-	pt := make([]byte, len(ct))
-	stream := cipher.NewCTR(block, iv)
-	stream.XORKeyStream(pt, ct)
+// NewCTRWriter returns a writer that encrypts data written to it into w with
+// AES-CTR, for data too large to hold in memory. A random IV is written to w
+// first, so the complete output is byte-compatible with EncryptCTR and can be
+// decrypted with DecryptCTR or NewCTRReader.
+//
+// NIST SP 800-38A Warning: This mode provides Confidentiality ONLY.
+//
+// Parameters:
+//   - w: destination for iv||ciphertext.
+//   - key: 16, 24, or 32 bytes (AES-128, 192, or 256).
+//
+// Returns an error if the IV cannot be generated or written.
+func NewCTRWriter(w io.Writer, key []byte) (io.Writer, error) {
+	return newStreamWriter(w, key, NewCTR)
+}
 
-	return pt, nil
+// NewCTRReader returns a reader that decrypts iv||ciphertext from r, as
+// produced by EncryptCTR or NewCTRWriter. The IV is read immediately.
+//
+// Parameters:
+//   - r: source of iv||ciphertext.
+//   - key: same key used for encryption.
+//
+// Returns an error if the IV cannot be read.
+func NewCTRReader(r io.Reader, key []byte) (io.Reader, error) {
+	return newStreamReader(r, key, NewCTR)
 }
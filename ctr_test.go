@@ -3,6 +3,7 @@ package goaes_test
 import (
 	"bytes"
 	"testing"
+	"testing/iotest"
 
 	goaes "github.com/fawwazid/go-aes"
 )
@@ -44,3 +45,74 @@ func TestAESCTR_EncryptDecrypt(t *testing.T) {
 		}
 	}
 }
+
+func TestAESCTR_NewCTR(t *testing.T) {
+	key := bytes.Repeat([]byte{0x01}, 16)
+	plaintext := []byte("Waltz, bad nymph, for quick jigs vex")
+
+	ct, err := goaes.EncryptCTR(key, plaintext)
+	if err != nil {
+		t.Fatalf("encrypt failed: %v", err)
+	}
+
+	stream, err := goaes.NewCTR(key, ct[:16])
+	if err != nil {
+		t.Fatalf("NewCTR failed: %v", err)
+	}
+	// Decrypt in two uneven pieces to exercise the stream state.
+	pt := make([]byte, len(ct)-16)
+	stream.XORKeyStream(pt[:5], ct[16:21])
+	stream.XORKeyStream(pt[5:], ct[21:])
+	if !bytes.Equal(pt, plaintext) {
+		t.Fatalf("plaintext mismatch: got %q", pt)
+	}
+
+	if _, err := goaes.NewCTR(key, make([]byte, 12)); err == nil {
+		t.Fatal("expected error for 12-byte IV")
+	}
+	if _, err := goaes.NewCTR(make([]byte, 10), make([]byte, 16)); err == nil {
+		t.Fatal("expected error for invalid key size")
+	}
+}
+
+func TestAESCTR_StreamWriterReader(t *testing.T) {
+	key := bytes.Repeat([]byte{0x31}, 32)
+	plaintext := bytes.Repeat([]byte("counter mode stream "), 5000)
+
+	// Written in uneven pieces, the output is the CTR one-shot format.
+	var buf bytes.Buffer
+	w, err := goaes.NewCTRWriter(&buf, key)
+	if err != nil {
+		t.Fatalf("NewCTRWriter failed: %v", err)
+	}
+	for p := plaintext; len(p) > 0; {
+		n := min(len(p), 1337)
+		if _, err := w.Write(p[:n]); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+		p = p[n:]
+	}
+	pt, err := goaes.DecryptCTR(key, buf.Bytes())
+	if err != nil || !bytes.Equal(pt, plaintext) {
+		t.Fatalf("DecryptCTR of writer output failed: %v", err)
+	}
+
+	ct, err := goaes.EncryptCTR(key, plaintext)
+	if err != nil {
+		t.Fatalf("encrypt failed: %v", err)
+	}
+	r, err := goaes.NewCTRReader(iotest.OneByteReader(bytes.NewReader(ct)), key)
+	if err != nil {
+		t.Fatalf("NewCTRReader failed: %v", err)
+	}
+	if err := iotest.TestReader(r, plaintext); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := goaes.NewCTRReader(bytes.NewReader(ct[:10]), key); err == nil {
+		t.Fatal("expected error for truncated IV")
+	}
+	if _, err := goaes.NewCTRWriter(&buf, key[:10]); err == nil {
+		t.Fatal("expected error for invalid key size")
+	}
+}
@@ -0,0 +1,151 @@
+package goaes
+
+import (
+	"crypto/cipher"
+	"crypto/subtle"
+	"errors"
+)
+
+// EncryptEAX encrypts plaintext using AES-EAX (CTR encryption with OMAC).
+//
+// EAX is a two-pass AEAD by Bellare, Rogaway and Wagner, used by several
+// embedded firmware and update formats. It is not a NIST-approved mode;
+// prefer EncryptGCM unless interoperability requires EAX.
+//
+// Parameters:
+//   - key: 16/24/32 bytes (AES-128/192/256).
+//   - plaintext: Data to be encrypted.
+//   - aad: Additional Authenticated Data (the EAX "header"; optional, can be nil).
+//   - nonceSize: nonce length in bytes (any positive value; 16 is typical).
+//   - tagSize: 4 to 16 bytes. Truncated tags weaken forgery resistance.
+//
+// Returns: nonce||ciphertext
+func EncryptEAX(key, plaintext, aad []byte, nonceSize, tagSize int) ([]byte, error) {
+	return SealEAX(nil, key, plaintext, aad, nonceSize, tagSize)
+}
+
+// DecryptEAX decrypts data produced by EncryptEAX.
+// It expects the nonce to be prepended to the ciphertext.
+//
+// Parameters:
+//   - key: same key used for encryption.
+//   - ciphertext: nonce||ciphertext.
+//   - aad: same additional data used for encryption.
+//   - nonceSize, tagSize: same values used for encryption.
+//
+// Returns: decrypted plaintext.
+func DecryptEAX(key, ciphertext, aad []byte, nonceSize, tagSize int) ([]byte, error) {
+	return OpenEAX(nil, key, ciphertext, aad, nonceSize, tagSize)
+}
+
+// SealEAX is the append-style form of EncryptEAX: it appends
+// nonce||ciphertext to dst and returns the updated slice. dst may overlap
+// plaintext, e.g. buf[:0] to encrypt buf in place; aad must not overlap dst.
+func SealEAX(dst, key, plaintext, aad []byte, nonceSize, tagSize int) ([]byte, error) {
+	aead, err := newEAX(key, nonceSize, tagSize)
+	if err != nil {
+		return nil, err
+	}
+	return sealPrefixed(dst, aead, plaintext, aad)
+}
+
+// OpenEAX is the append-style form of DecryptEAX: it appends the plaintext
+// to dst and returns the updated slice. dst may overlap ciphertext, e.g.
+// buf[:0] to decrypt buf in place.
+func OpenEAX(dst, key, ciphertext, aad []byte, nonceSize, tagSize int) ([]byte, error) {
+	aead, err := newEAX(key, nonceSize, tagSize)
+	if err != nil {
+		return nil, err
+	}
+	return openPrefixed(dst, aead, ciphertext, aad)
+}
+
+// NewEAX returns AES-EAX as a cipher.AEAD with the given nonce and tag sizes.
+// The caller is responsible for never reusing a nonce.
+func NewEAX(key []byte, nonceSize, tagSize int) (cipher.AEAD, error) {
+	return newEAX(key, nonceSize, tagSize)
+}
+
+// eax implements cipher.AEAD for AES-EAX.
+type eax struct {
+	mac       *cmacKey
+	nonceSize int
+	tagSize   int
+}
+
+// newEAX validates the key and parameters and returns an EAX AEAD.
+func newEAX(key []byte, nonceSize, tagSize int) (*eax, error) {
+	if nonceSize <= 0 {
+		return nil, errors.New("invalid EAX nonce size: must be positive")
+	}
+	if tagSize < 4 || tagSize > 16 {
+		return nil, errors.New("invalid EAX tag size: must be 4 to 16 bytes")
+	}
+
+	block, err := newCipherBlock(key)
+	if err != nil {
+		return nil, err
+	}
+	return &eax{mac: newCMACKey(block), nonceSize: nonceSize, tagSize: tagSize}, nil
+}
+
+func (e *eax) NonceSize() int { return e.nonceSize }
+
+func (e *eax) Overhead() int { return e.tagSize }
+
+func (e *eax) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
+	if len(nonce) != e.nonceSize {
+		panic("goaes: incorrect nonce length given to EAX")
+	}
+
+	n := e.omac(0, nonce)
+	h := e.omac(1, additionalData)
+
+	ret, out := sliceForAppend(dst, len(plaintext)+e.tagSize)
+	ct := out[:len(plaintext)]
+	cipher.NewCTR(e.mac.block, n[:]).XORKeyStream(ct, plaintext)
+
+	c := e.omac(2, ct)
+	for i := 0; i < e.tagSize; i++ {
+		out[len(plaintext)+i] = n[i] ^ h[i] ^ c[i]
+	}
+	return ret
+}
+
+func (e *eax) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(nonce) != e.nonceSize {
+		panic("goaes: incorrect nonce length given to EAX")
+	}
+	if len(ciphertext) < e.tagSize {
+		return nil, errOpen
+	}
+
+	ctLen := len(ciphertext) - e.tagSize
+	ct := ciphertext[:ctLen]
+
+	n := e.omac(0, nonce)
+	h := e.omac(1, additionalData)
+	c := e.omac(2, ct)
+
+	var expected [16]byte
+	subtle.XORBytes(expected[:], n[:], h[:])
+	subtle.XORBytes(expected[:], expected[:], c[:])
+	if subtle.ConstantTimeCompare(expected[:e.tagSize], ciphertext[ctLen:]) != 1 {
+		return nil, errOpen
+	}
+
+	ret, out := sliceForAppend(dst, ctLen)
+	cipher.NewCTR(e.mac.block, n[:]).XORKeyStream(out, ct)
+	return ret, nil
+}
+
+// omac computes OMAC^t(msg), which is CMAC over the block [t]_16 || msg.
+func (e *eax) omac(t byte, msg []byte) [16]byte {
+	var prefix [16]byte
+	prefix[15] = t
+	if len(msg) == 0 {
+		return e.mac.sum(prefix[:])
+	}
+	e.mac.block.Encrypt(prefix[:], prefix[:])
+	return e.mac.sumFrom(prefix, msg)
+}
@@ -0,0 +1,134 @@
+package goaes_test
+
+import (
+	"bytes"
+	"testing"
+
+	goaes "github.com/fawwazid/go-aes"
+)
+
+func TestAESEAX_EncryptDecrypt(t *testing.T) {
+	plaintext := []byte("Waltz, bad nymph, for quick jigs vex")
+	aad := []byte("firmware-header")
+
+	for _, k := range []int{16, 24, 32} {
+		key := make([]byte, k)
+		for i := 0; i < k; i++ {
+			key[i] = byte(i)
+		}
+
+		for _, p := range []struct{ nonce, tag int }{{16, 16}, {12, 8}, {1, 4}, {32, 16}} {
+			ct, err := goaes.EncryptEAX(key, plaintext, aad, p.nonce, p.tag)
+			if err != nil {
+				t.Fatalf("encrypt failed for key len %d (%+v): %v", k, p, err)
+			}
+			if len(ct) != p.nonce+len(plaintext)+p.tag {
+				t.Fatalf("unexpected ciphertext length %d for key len %d (%+v)", len(ct), k, p)
+			}
+
+			pt, err := goaes.DecryptEAX(key, ct, aad, p.nonce, p.tag)
+			if err != nil {
+				t.Fatalf("decrypt failed for key len %d (%+v): %v", k, p, err)
+			}
+
+			if !bytes.Equal(pt, plaintext) {
+				t.Fatalf("plaintext mismatch for key len %d (%+v)", k, p)
+			}
+
+			// tamper detection: flip last byte
+			bad := make([]byte, len(ct))
+			copy(bad, ct)
+			bad[len(bad)-1] ^= 0xFF
+			_, err = goaes.DecryptEAX(key, bad, aad, p.nonce, p.tag)
+			if err == nil {
+				t.Fatalf("expected decryption error for tampered ciphertext (key len %d, %+v)", k, p)
+			}
+		}
+	}
+}
+
+func TestAESEAX_InvalidParams(t *testing.T) {
+	key := make([]byte, 16)
+
+	_, err := goaes.EncryptEAX([]byte("invalid-key"), []byte("secret"), nil, 16, 16)
+	if err == nil {
+		t.Error("expected error for invalid key size in EncryptEAX")
+	}
+	if _, err := goaes.NewEAX(key, 0, 16); err == nil {
+		t.Error("expected error for zero nonce size")
+	}
+	for _, tag := range []int{0, 3, 17} {
+		if _, err := goaes.NewEAX(key, 16, tag); err == nil {
+			t.Errorf("expected error for tag size %d", tag)
+		}
+	}
+	_, err = goaes.DecryptEAX(key, []byte("short"), nil, 16, 16)
+	if err == nil {
+		t.Error("expected error for short ciphertext in DecryptEAX")
+	}
+}
+
+// TestAESEAX_Vectors checks test vectors from the EAX paper
+// (Bellare, Rogaway and Wagner, Appendix E).
+func TestAESEAX_Vectors(t *testing.T) {
+	tests := []struct {
+		msg, key, nonce, header, cipher string
+	}{
+		{
+			msg:    "",
+			key:    "233952dee4d5ed5f9b9c6d6ff80ff478",
+			nonce:  "62ec67f9c3a4a407fcb2a8c49031a8b3",
+			header: "6bfb914fd07eae6b",
+			cipher: "e037830e8389f27b025a2d6527e79d01",
+		},
+		{
+			msg:    "f7fb",
+			key:    "91945d3f4dcbee0bf45ef52255f095a4",
+			nonce:  "becaf043b0a23d843194ba972c66debd",
+			header: "fa3bfd4806eb53fa",
+			cipher: "19dd5c4c9331049d0bdab0277408f67967e5",
+		},
+		{
+			msg:    "1a47cb4933",
+			key:    "01f74ad64077f2e704c0f60ada3dd523",
+			nonce:  "70c3db4f0d26368400a10ed05d2bff5e",
+			header: "234a3463c1264ac6",
+			cipher: "d851d5bae03a59f238a23e39199dc9266626c40f80",
+		},
+		{
+			msg:    "481c9e39b1",
+			key:    "d07cf6cbb7f313bdde66b727afd3c5e8",
+			nonce:  "8408dfff3c1a2b1292dc199e46b7d617",
+			header: "33cce2eabff5a79d",
+			cipher: "632a9d131ad4c168a4225d8e1ff755939974a7bede",
+		},
+		{
+			msg:    "40d0c07da5e4",
+			key:    "35b6d0580005bbc12b0587124557d2c2",
+			nonce:  "fdb6b06676eedc5c61d74276e1f8e816",
+			header: "aeb96eaebe2970e9",
+			cipher: "071dfe16c675cb0677e536f73afe6a14b74ee49844dd",
+		},
+	}
+
+	for i, tt := range tests {
+		nonce := mustHex(t, tt.nonce)
+		aead, err := goaes.NewEAX(mustHex(t, tt.key), len(nonce), 16)
+		if err != nil {
+			t.Fatalf("vector %d: NewEAX failed: %v", i, err)
+		}
+
+		ct := aead.Seal(nil, nonce, mustHex(t, tt.msg), mustHex(t, tt.header))
+		if !bytes.Equal(ct, mustHex(t, tt.cipher)) {
+			t.Fatalf("vector %d: ciphertext = %x, want %s", i, ct, tt.cipher)
+		}
+
+		pt, err := aead.Open(nil, nonce, ct, mustHex(t, tt.header))
+		if err != nil {
+			t.Fatalf("vector %d: open failed: %v", i, err)
+		}
+		if !bytes.Equal(pt, mustHex(t, tt.msg)) {
+			t.Fatalf("vector %d: plaintext = %x, want %s", i, pt, tt.msg)
+		}
+	}
+}
@@ -18,30 +18,44 @@ import (
 //
 // Returns: ciphertext (no IV used in ECB).
 func EncryptECB(key, plaintext []byte) ([]byte, error) {
+	return SealECB(nil, key, plaintext)
+}
+
+// DecryptECB decrypts ciphertext produced by EncryptECB and removes PKCS#7 padding.
+//
+// Parameters:
+//   - key: same key used for encryption.
+//   - ciphertext: Data to be decrypted.
+//
+// Returns: decrypted plaintext (unpadded).
+func DecryptECB(key, ciphertext []byte) ([]byte, error) {
+	return OpenECB(nil, key, ciphertext)
+}
+
+// SealECB is the append-style form of EncryptECB: it appends the ciphertext
+// to dst and returns the updated slice. Padding is written directly into the
+// output. dst may overlap plaintext, e.g. buf[:0] to encrypt buf in place.
+func SealECB(dst, key, plaintext []byte) ([]byte, error) {
 	block, err := newCipherBlock(key)
 	if err != nil {
 		return nil, err
 	}
 
 	bs := block.BlockSize()
-	padded := pkcs7Pad(plaintext, bs)
+	ret, ct := sliceForAppend(dst, pkcs7PaddedLen(len(plaintext), bs))
+	pkcs7Fill(ct, copy(ct, plaintext))
 
-	ct := make([]byte, len(padded))
-	for i := 0; i < len(padded); i += bs {
-		block.Encrypt(ct[i:i+bs], padded[i:i+bs])
+	for i := 0; i < len(ct); i += bs {
+		block.Encrypt(ct[i:i+bs], ct[i:i+bs])
 	}
 
-	return ct, nil
+	return ret, nil
 }
 
-// DecryptECB decrypts ciphertext produced by EncryptECB and removes PKCS#7 padding.
-//
-// Parameters:
-//   - key: same key used for encryption.
-//   - ciphertext: Data to be decrypted.
-//
-// Returns: decrypted plaintext (unpadded).
-func DecryptECB(key, ciphertext []byte) ([]byte, error) {
+// OpenECB is the append-style form of DecryptECB: it appends the unpadded
+// plaintext to dst and returns the updated slice. dst may overlap
+// ciphertext, e.g. buf[:0] to decrypt buf in place.
+func OpenECB(dst, key, ciphertext []byte) ([]byte, error) {
 	block, err := newCipherBlock(key)
 	if err != nil {
 		return nil, err
@@ -52,10 +66,18 @@ func DecryptECB(key, ciphertext []byte) ([]byte, error) {
 		return nil, errors.New("ciphertext is not a multiple of the block size")
 	}
 
-	pt := make([]byte, len(ciphertext))
+	ret, pt := sliceForAppend(dst, len(ciphertext))
+	if anyOverlap(pt, ciphertext) {
+		ciphertext = pt[:copy(pt, ciphertext)]
+	}
 	for i := 0; i < len(ciphertext); i += bs {
 		block.Decrypt(pt[i:i+bs], ciphertext[i:i+bs])
 	}
 
-	return pkcs7Unpad(pt, bs)
+	unpadded, err := pkcs7Unpad(pt, bs)
+	if err != nil {
+		clear(pt)
+		return nil, err
+	}
+	return ret[:len(dst)+len(unpadded)], nil
 }
@@ -0,0 +1,207 @@
+package goaes
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+)
+
+// etmTagSize is the HMAC-SHA256 tag length appended by the *HMAC functions.
+const etmTagSize = sha256.Size
+
+// EncryptCTRHMAC encrypts plaintext with AES-CTR and authenticates it with
+// HMAC-SHA256 (Encrypt-then-MAC).
+//
+// Separate encryption and MAC subkeys are derived from key with HKDF-SHA256,
+// so a single master key is safe to use. The tag covers the IV, ciphertext
+// and AAD, and is checked before anything is decrypted.
+//
+// Recommendation: Use EncryptGCM (AEAD) for new designs; use this when CTR is required.
+//
+// Parameters:
+//   - key: 16, 24, or 32 bytes (AES-128, 192, or 256) master key.
+//   - plaintext: Data to be encrypted.
+//   - aad: Additional Authenticated Data (optional, can be nil).
+//
+// Returns: iv||ciphertext||tag
+func EncryptCTRHMAC(key, plaintext, aad []byte) ([]byte, error) {
+	return SealCTRHMAC(nil, key, plaintext, aad)
+}
+
+// DecryptCTRHMAC verifies and decrypts data produced by EncryptCTRHMAC.
+//
+// Parameters:
+//   - key: same master key used for encryption.
+//   - ciphertext: iv||ciphertext||tag.
+//   - aad: same additional data used for encryption.
+//
+// Returns: decrypted plaintext.
+func DecryptCTRHMAC(key, ciphertext, aad []byte) ([]byte, error) {
+	return OpenCTRHMAC(nil, key, ciphertext, aad)
+}
+
+// SealCTRHMAC is the append-style form of EncryptCTRHMAC: it appends
+// iv||ciphertext||tag to dst and returns the updated slice. dst may overlap
+// plaintext, e.g. buf[:0] to encrypt buf in place; aad must not overlap dst.
+func SealCTRHMAC(dst, key, plaintext, aad []byte) ([]byte, error) {
+	return etmSeal(dst, "ctr", NewCTR, key, plaintext, aad)
+}
+
+// OpenCTRHMAC is the append-style form of DecryptCTRHMAC: it appends the
+// plaintext to dst and returns the updated slice. dst may overlap
+// ciphertext, e.g. buf[:0] to decrypt buf in place.
+func OpenCTRHMAC(dst, key, ciphertext, aad []byte) ([]byte, error) {
+	return etmOpen(dst, "ctr", NewCTR, key, ciphertext, aad)
+}
+
+// EncryptCFBHMAC encrypts plaintext with AES-CFB and authenticates it with
+// HMAC-SHA256 (Encrypt-then-MAC). See EncryptCTRHMAC for details.
+//
+// Parameters:
+//   - key: 16, 24, or 32 bytes (AES-128, 192, or 256) master key.
+//   - plaintext: Data to be encrypted.
+//   - aad: Additional Authenticated Data (optional, can be nil).
+//
+// Returns: iv||ciphertext||tag
+func EncryptCFBHMAC(key, plaintext, aad []byte) ([]byte, error) {
+	return SealCFBHMAC(nil, key, plaintext, aad)
+}
+
+// DecryptCFBHMAC verifies and decrypts data produced by EncryptCFBHMAC.
+//
+// Parameters:
+//   - key: same master key used for encryption.
+//   - ciphertext: iv||ciphertext||tag.
+//   - aad: same additional data used for encryption.
+//
+// Returns: decrypted plaintext.
+func DecryptCFBHMAC(key, ciphertext, aad []byte) ([]byte, error) {
+	return OpenCFBHMAC(nil, key, ciphertext, aad)
+}
+
+// SealCFBHMAC is the append-style form of EncryptCFBHMAC: it appends
+// iv||ciphertext||tag to dst and returns the updated slice. dst may overlap
+// plaintext, e.g. buf[:0] to encrypt buf in place; aad must not overlap dst.
+func SealCFBHMAC(dst, key, plaintext, aad []byte) ([]byte, error) {
+	return etmSeal(dst, "cfb", NewCFBEncrypter, key, plaintext, aad)
+}
+
+// OpenCFBHMAC is the append-style form of DecryptCFBHMAC: it appends the
+// plaintext to dst and returns the updated slice. dst may overlap
+// ciphertext, e.g. buf[:0] to decrypt buf in place.
+func OpenCFBHMAC(dst, key, ciphertext, aad []byte) ([]byte, error) {
+	return etmOpen(dst, "cfb", NewCFBDecrypter, key, ciphertext, aad)
+}
+
+// EncryptOFBHMAC encrypts plaintext with AES-OFB and authenticates it with
+// HMAC-SHA256 (Encrypt-then-MAC). See EncryptCTRHMAC for details.
+//
+// Parameters:
+//   - key: 16, 24, or 32 bytes (AES-128, 192, or 256) master key.
+//   - plaintext: Data to be encrypted.
+//   - aad: Additional Authenticated Data (optional, can be nil).
+//
+// Returns: iv||ciphertext||tag
+func EncryptOFBHMAC(key, plaintext, aad []byte) ([]byte, error) {
+	return SealOFBHMAC(nil, key, plaintext, aad)
+}
+
+// DecryptOFBHMAC verifies and decrypts data produced by EncryptOFBHMAC.
+//
+// Parameters:
+//   - key: same master key used for encryption.
+//   - ciphertext: iv||ciphertext||tag.
+//   - aad: same additional data used for encryption.
+//
+// Returns: decrypted plaintext.
+func DecryptOFBHMAC(key, ciphertext, aad []byte) ([]byte, error) {
+	return OpenOFBHMAC(nil, key, ciphertext, aad)
+}
+
+// SealOFBHMAC is the append-style form of EncryptOFBHMAC: it appends
+// iv||ciphertext||tag to dst and returns the updated slice. dst may overlap
+// plaintext, e.g. buf[:0] to encrypt buf in place; aad must not overlap dst.
+func SealOFBHMAC(dst, key, plaintext, aad []byte) ([]byte, error) {
+	return etmSeal(dst, "ofb", NewOFB, key, plaintext, aad)
+}
+
+// OpenOFBHMAC is the append-style form of DecryptOFBHMAC: it appends the
+// plaintext to dst and returns the updated slice. dst may overlap
+// ciphertext, e.g. buf[:0] to decrypt buf in place.
+func OpenOFBHMAC(dst, key, ciphertext, aad []byte) ([]byte, error) {
+	return etmOpen(dst, "ofb", NewOFB, key, ciphertext, aad)
+}
+
+// etmKeys derives the mode-specific AES and HMAC subkeys from the master key.
+// The caller should clear both when done.
+func etmKeys(mode string, key []byte) ([]byte, []byte, error) {
+	if err := validateKeySize(key); err != nil {
+		return nil, nil, err
+	}
+
+	encKey, err := hkdf.Key(sha256.New, key, nil, "goaes "+mode+"-hmac-sha256 encryption", len(key))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	macKey, err := hkdf.Key(sha256.New, key, nil, "goaes "+mode+"-hmac-sha256 authentication", sha256.Size)
+	if err != nil {
+		clear(encKey)
+		return nil, nil, err
+	}
+	return encKey, macKey, nil
+}
+
+// etmTag appends HMAC-SHA256 over iv||ciphertext||aad||len(aad) to dst,
+// where the AAD length is a 64-bit big-endian byte count.
+func etmTag(dst, macKey, ivAndCiphertext, aad []byte) []byte {
+	m := hmac.New(sha256.New, macKey)
+	m.Write(ivAndCiphertext)
+	m.Write(aad)
+	var al [8]byte
+	binary.BigEndian.PutUint64(al[:], uint64(len(aad)))
+	m.Write(al[:])
+	return m.Sum(dst)
+}
+
+// etmSeal encrypts with the given stream mode, then appends the tag.
+func etmSeal(dst []byte, mode string, newStream func(key, iv []byte) (cipher.Stream, error), key, plaintext, aad []byte) ([]byte, error) {
+	encKey, macKey, err := etmKeys(mode, key)
+	if err != nil {
+		return nil, err
+	}
+	defer clear(encKey)
+	defer clear(macKey)
+
+	ret, err := sealStream(dst, encKey, newStream, plaintext)
+	if err != nil {
+		return nil, err
+	}
+	return etmTag(ret, macKey, ret[len(dst):], aad), nil
+}
+
+// etmOpen verifies the tag, then decrypts with the given stream mode.
+func etmOpen(dst []byte, mode string, newStream func(key, iv []byte) (cipher.Stream, error), key, ciphertext, aad []byte) ([]byte, error) {
+	encKey, macKey, err := etmKeys(mode, key)
+	if err != nil {
+		return nil, err
+	}
+	defer clear(encKey)
+	defer clear(macKey)
+
+	if len(ciphertext) < aes.BlockSize+etmTagSize {
+		return nil, errors.New("ciphertext too short")
+	}
+
+	body := ciphertext[:len(ciphertext)-etmTagSize]
+	var expected [etmTagSize]byte
+	if !hmac.Equal(etmTag(expected[:0], macKey, body, aad), ciphertext[len(body):]) {
+		return nil, errOpen
+	}
+
+	return openStream(dst, encKey, newStream, body)
+}
@@ -0,0 +1,92 @@
+package goaes_test
+
+import (
+	"bytes"
+	"testing"
+
+	goaes "github.com/fawwazid/go-aes"
+)
+
+var etmModes = []struct {
+	name    string
+	encrypt func(key, plaintext, aad []byte) ([]byte, error)
+	decrypt func(key, ciphertext, aad []byte) ([]byte, error)
+}{
+	{"CTR", goaes.EncryptCTRHMAC, goaes.DecryptCTRHMAC},
+	{"CFB", goaes.EncryptCFBHMAC, goaes.DecryptCFBHMAC},
+	{"OFB", goaes.EncryptOFBHMAC, goaes.DecryptOFBHMAC},
+}
+
+func TestEncryptThenMAC_EncryptDecrypt(t *testing.T) {
+	plaintext := []byte("Malleable modes need a MAC around them")
+	aad := []byte("header-aad")
+
+	for _, k := range []int{16, 24, 32} {
+		key := make([]byte, k)
+		for i := 0; i < k; i++ {
+			key[i] = byte(i)
+		}
+
+		for _, mode := range etmModes {
+			ct, err := mode.encrypt(key, plaintext, aad)
+			if err != nil {
+				t.Fatalf("%s encrypt failed for key len %d: %v", mode.name, k, err)
+			}
+			if len(ct) != 16+len(plaintext)+32 {
+				t.Fatalf("%s: ciphertext len = %d, want %d", mode.name, len(ct), 16+len(plaintext)+32)
+			}
+
+			pt, err := mode.decrypt(key, ct, aad)
+			if err != nil {
+				t.Fatalf("%s decrypt failed for key len %d: %v", mode.name, k, err)
+			}
+			if !bytes.Equal(pt, plaintext) {
+				t.Fatalf("%s: plaintext mismatch for key len %d", mode.name, k)
+			}
+
+			// tamper detection: IV, ciphertext body and tag
+			for _, pos := range []int{0, 20, len(ct) - 1} {
+				bad := make([]byte, len(ct))
+				copy(bad, ct)
+				bad[pos] ^= 0xFF
+				if _, err := mode.decrypt(key, bad, aad); err == nil {
+					t.Fatalf("%s: expected error for tampered byte %d (key len %d)", mode.name, pos, k)
+				}
+			}
+
+			if _, err := mode.decrypt(key, ct, []byte("other-aad")); err == nil {
+				t.Fatalf("%s: expected error for wrong aad (key len %d)", mode.name, k)
+			}
+		}
+	}
+}
+
+func TestEncryptThenMAC_ModesAreSeparated(t *testing.T) {
+	key := make([]byte, 32)
+	ct, err := goaes.EncryptCTRHMAC(key, []byte("secret"), nil)
+	if err != nil {
+		t.Fatalf("encrypt failed: %v", err)
+	}
+	if _, err := goaes.DecryptOFBHMAC(key, ct, nil); err == nil {
+		t.Fatal("expected CTR ciphertext to be rejected by DecryptOFBHMAC")
+	}
+	// The derived encryption key differs from the master key.
+	pt, err := goaes.DecryptCTR(key, ct[:len(ct)-32])
+	if err != nil {
+		t.Fatalf("DecryptCTR failed: %v", err)
+	}
+	if bytes.Equal(pt, []byte("secret")) {
+		t.Fatal("expected the master key not to be used directly for encryption")
+	}
+}
+
+func TestEncryptThenMAC_InvalidInput(t *testing.T) {
+	for _, mode := range etmModes {
+		if _, err := mode.encrypt([]byte("invalid-key"), []byte("secret"), nil); err == nil {
+			t.Errorf("%s: expected error for invalid key size", mode.name)
+		}
+		if _, err := mode.decrypt(make([]byte, 16), make([]byte, 47), nil); err == nil {
+			t.Errorf("%s: expected error for short ciphertext", mode.name)
+		}
+	}
+}
@@ -0,0 +1,428 @@
+package goaes
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"errors"
+	"math/big"
+	"slices"
+)
+
+// Common alphabets for format-preserving encryption. Any string of distinct
+// characters (2 to 65536 of them) may be used; its length is the radix.
+const (
+	// AlphabetDigits is the radix-10 alphabet for card numbers, SSNs and phone numbers.
+	AlphabetDigits = "0123456789"
+	// AlphabetLowerAlphanumeric is the radix-36 alphabet used by the NIST FF1 samples.
+	AlphabetLowerAlphanumeric = "0123456789abcdefghijklmnopqrstuvwxyz"
+	// AlphabetAlphanumeric is the radix-62 alphabet of digits and both letter cases.
+	AlphabetAlphanumeric = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+)
+
+const (
+	// fpeMinDomain is the minimum domain size radix^len (SP 800-38G Rev. 1).
+	fpeMinDomain = 1000000
+	// ff1MaxLen is the maximum FF1 input length (2^32 numerals).
+	ff1MaxLen uint64 = 1 << 32
+	// ff31TweakSize is the FF3-1 tweak length in bytes (56 bits).
+	ff31TweakSize = 7
+)
+
+// EncryptFF1 encrypts plaintext with the FF1 format-preserving encryption mode.
+//
+// NIST SP 800-38G Recommendation: The ciphertext has the same length and
+// alphabet as the plaintext, so it fits existing columns and validators.
+// FPE is deterministic for a given key and tweak, so equal inputs give equal
+// outputs; vary the tweak (e.g. by record or column) where possible.
+//
+// Parameters:
+//   - key: 16, 24, or 32 bytes (AES-128, 192, or 256).
+//   - tweak: public, non-secret variability (optional, can be nil).
+//   - alphabet: the characters plaintext is drawn from, e.g. AlphabetDigits.
+//   - plaintext: at least 2 characters, with len(alphabet)^len(plaintext) >= 1,000,000.
+//
+// Returns: ciphertext over the same alphabet.
+func EncryptFF1(key, tweak []byte, alphabet, plaintext string) (string, error) {
+	return ff1Crypt(key, tweak, alphabet, plaintext, true)
+}
+
+// DecryptFF1 decrypts ciphertext produced by EncryptFF1.
+//
+// Parameters:
+//   - key: same key used for encryption.
+//   - tweak: same tweak used for encryption.
+//   - alphabet: same alphabet used for encryption.
+//   - ciphertext: Data to be decrypted.
+//
+// Returns: decrypted plaintext.
+func DecryptFF1(key, tweak []byte, alphabet, ciphertext string) (string, error) {
+	return ff1Crypt(key, tweak, alphabet, ciphertext, false)
+}
+
+// EncryptFF31 encrypts plaintext with the FF3-1 format-preserving encryption mode.
+//
+// NIST SP 800-38G Rev. 1 Recommendation: FF3-1 replaces FF3 with a 56-bit
+// tweak. Its maximum input length is limited (e.g. 56 decimal digits); use
+// EncryptFF1 for longer inputs.
+//
+// Parameters:
+//   - key: 16, 24, or 32 bytes (AES-128, 192, or 256).
+//   - tweak: exactly 7 bytes.
+//   - alphabet: the characters plaintext is drawn from, e.g. AlphabetDigits.
+//   - plaintext: at least 2 characters, with len(alphabet)^len(plaintext) >= 1,000,000.
+//
+// Returns: ciphertext over the same alphabet.
+func EncryptFF31(key, tweak []byte, alphabet, plaintext string) (string, error) {
+	return ff31Crypt(key, tweak, alphabet, plaintext, true)
+}
+
+// DecryptFF31 decrypts ciphertext produced by EncryptFF31.
+//
+// Parameters:
+//   - key: same key used for encryption.
+//   - tweak: same 7-byte tweak used for encryption.
+//   - alphabet: same alphabet used for encryption.
+//   - ciphertext: Data to be decrypted.
+//
+// Returns: decrypted plaintext.
+func DecryptFF31(key, tweak []byte, alphabet, ciphertext string) (string, error) {
+	return ff31Crypt(key, tweak, alphabet, ciphertext, false)
+}
+
+// ff1Crypt validates the inputs and runs FF1 in either direction.
+func ff1Crypt(key, tweak []byte, alphabet, input string, encrypt bool) (string, error) {
+	block, err := newCipherBlock(key)
+	if err != nil {
+		return "", err
+	}
+
+	a, err := newFPEAlphabet(alphabet)
+	if err != nil {
+		return "", err
+	}
+	x, err := a.numerals(input)
+	if err != nil {
+		return "", err
+	}
+	if err := validateFPELength(a.radix(), len(x)); err != nil {
+		return "", err
+	}
+	if uint64(len(x)) > ff1MaxLen {
+		return "", errors.New("FPE input too long for FF1")
+	}
+
+	return a.format(ff1(block, a.radix(), tweak, x, encrypt)), nil
+}
+
+// ff31Crypt validates the inputs and runs FF3-1 in either direction.
+func ff31Crypt(key, tweak []byte, alphabet, input string, encrypt bool) (string, error) {
+	if err := validateKeySize(key); err != nil {
+		return "", err
+	}
+	if len(tweak) != ff31TweakSize {
+		return "", errors.New("invalid FF3-1 tweak size: must be 7 bytes")
+	}
+
+	a, err := newFPEAlphabet(alphabet)
+	if err != nil {
+		return "", err
+	}
+	x, err := a.numerals(input)
+	if err != nil {
+		return "", err
+	}
+	if err := validateFPELength(a.radix(), len(x)); err != nil {
+		return "", err
+	}
+	if len(x) > ff3MaxLen(a.radix()) {
+		return "", errors.New("FPE input too long for FF3-1")
+	}
+
+	block, err := newFF3Cipher(key)
+	if err != nil {
+		return "", err
+	}
+
+	// TL = T[0..27] || 0^4, TR = T[32..55] || T[28..31] || 0^4.
+	var tl, tr [4]byte
+	copy(tl[:3], tweak[:3])
+	tl[3] = tweak[3] & 0xf0
+	copy(tr[:3], tweak[4:])
+	tr[3] = tweak[3] << 4
+
+	return a.format(ff3(block, a.radix(), tl, tr, x, encrypt)), nil
+}
+
+// ff1 implements FF1.Encrypt and FF1.Decrypt (SP 800-38G, Algorithms 7 and 8).
+func ff1(block cipher.Block, radix int, tweak []byte, x []uint16, encrypt bool) []uint16 {
+	n := len(x)
+	u := n / 2
+	v := n - u
+	a := slices.Clone(x[:u])
+	b := slices.Clone(x[u:])
+
+	bigRadix := big.NewInt(int64(radix))
+	modU := new(big.Int).Exp(bigRadix, big.NewInt(int64(u)), nil)
+	modV := new(big.Int).Exp(bigRadix, big.NewInt(int64(v)), nil)
+
+	// b = ceil(ceil(v*log2(radix))/8), d = 4*ceil(b/4) + 4
+	numLen := (new(big.Int).Sub(modV, big.NewInt(1)).BitLen() + 7) / 8
+	d := 4*((numLen+3)/4) + 4
+
+	t := len(tweak)
+	p := make([]byte, 16, 16+t+31+numLen)
+	p[0], p[1], p[2] = 1, 2, 1
+	p[3], p[4], p[5] = byte(radix>>16), byte(radix>>8), byte(radix)
+	p[6] = 10
+	p[7] = byte(u)
+	binary.BigEndian.PutUint32(p[8:12], uint32(n))
+	binary.BigEndian.PutUint32(p[12:16], uint32(t))
+
+	// Q = T || [0]^((-t-b-1) mod 16) || [i]^1 || [NUM_radix(B)]^b
+	padLen := (16 - (t+numLen+1)%16) % 16
+	pq := append(p, tweak...)
+	pq = append(pq, make([]byte, padLen+1+numLen)...)
+	roundIdx := len(pq) - numLen - 1
+
+	y := new(big.Int)
+	c := new(big.Int)
+	for round := 0; round < 10; round++ {
+		i := round
+		src, dst := b, a
+		if !encrypt {
+			i = 9 - round
+			src, dst = a, b
+		}
+
+		pq[roundIdx] = byte(i)
+		fpeNum(src, radix).FillBytes(pq[len(pq)-numLen:])
+
+		r := ff1PRF(block, pq)
+		y.SetBytes(ff1Expand(block, r, d))
+
+		m, mod := u, modU
+		if i%2 == 1 {
+			m, mod = v, modV
+		}
+
+		c.Set(fpeNum(dst, radix))
+		if encrypt {
+			c.Add(c, y)
+		} else {
+			c.Sub(c, y)
+		}
+		c.Mod(c, mod)
+		out := fpeStr(c, radix, m)
+
+		if encrypt {
+			a, b = b, out
+		} else {
+			b, a = a, out
+		}
+	}
+	return append(a, b...)
+}
+
+// ff1PRF computes the CBC-MAC of data, whose length is a multiple of 16 bytes.
+func ff1PRF(block cipher.Block, data []byte) [16]byte {
+	var r [16]byte
+	for i := 0; i < len(data); i += 16 {
+		for j := 0; j < 16; j++ {
+			r[j] ^= data[i+j]
+		}
+		block.Encrypt(r[:], r[:])
+	}
+	return r
+}
+
+// ff1Expand returns the first d bytes of R || CIPH(R xor [1]^16) || CIPH(R xor [2]^16) ...
+func ff1Expand(block cipher.Block, r [16]byte, d int) []byte {
+	s := make([]byte, 0, d+16)
+	s = append(s, r[:]...)
+	for j := uint64(1); len(s) < d; j++ {
+		var in [16]byte
+		copy(in[:], r[:])
+		var ctr [8]byte
+		binary.BigEndian.PutUint64(ctr[:], j)
+		for k := 0; k < 8; k++ {
+			in[8+k] ^= ctr[k]
+		}
+		block.Encrypt(in[:], in[:])
+		s = append(s, in[:]...)
+	}
+	return s[:d]
+}
+
+// ff3 implements FF3.Encrypt and FF3.Decrypt (SP 800-38G, Algorithms 9 and 10)
+// given the tweak halves TL and TR. block must be keyed with REVB(K).
+func ff3(block cipher.Block, radix int, tl, tr [4]byte, x []uint16, encrypt bool) []uint16 {
+	n := len(x)
+	u := (n + 1) / 2
+	v := n - u
+	a := slices.Clone(x[:u])
+	b := slices.Clone(x[u:])
+
+	bigRadix := big.NewInt(int64(radix))
+	modU := new(big.Int).Exp(bigRadix, big.NewInt(int64(u)), nil)
+	modV := new(big.Int).Exp(bigRadix, big.NewInt(int64(v)), nil)
+
+	y := new(big.Int)
+	c := new(big.Int)
+	for round := 0; round < 8; round++ {
+		i := round
+		src, dst := b, a
+		if !encrypt {
+			i = 7 - round
+			src, dst = a, b
+		}
+
+		m, mod, w := u, modU, tr
+		if i%2 == 1 {
+			m, mod, w = v, modV, tl
+		}
+
+		// P = W xor [i]^4 || [NUM_radix(REV(src))]^12
+		var p [16]byte
+		copy(p[:4], w[:])
+		p[3] ^= byte(i)
+		fpeNum(fpeRev(src), radix).FillBytes(p[4:])
+
+		// S = REVB(CIPH_REVB(K)(REVB(P)))
+		slices.Reverse(p[:])
+		block.Encrypt(p[:], p[:])
+		slices.Reverse(p[:])
+		y.SetBytes(p[:])
+
+		c.Set(fpeNum(fpeRev(dst), radix))
+		if encrypt {
+			c.Add(c, y)
+		} else {
+			c.Sub(c, y)
+		}
+		c.Mod(c, mod)
+		out := fpeRev(fpeStr(c, radix, m))
+
+		if encrypt {
+			a, b = b, out
+		} else {
+			b, a = a, out
+		}
+	}
+	return append(a, b...)
+}
+
+// newFF3Cipher returns the AES cipher keyed with the byte-reversed key.
+func newFF3Cipher(key []byte) (cipher.Block, error) {
+	rev := slices.Clone(key)
+	slices.Reverse(rev)
+	defer clear(rev)
+	return aes.NewCipher(rev)
+}
+
+// ff3MaxLen returns 2*floor(log_radix(2^96)), the FF3-1 maximum input length.
+func ff3MaxLen(radix int) int {
+	limit := new(big.Int).Lsh(big.NewInt(1), 96)
+	bigRadix := big.NewInt(int64(radix))
+	p := big.NewInt(1)
+	m := 0
+	for {
+		p.Mul(p, bigRadix)
+		if p.Cmp(limit) > 0 {
+			return 2 * m
+		}
+		m++
+	}
+}
+
+// validateFPELength checks the SP 800-38G minimum length and domain size.
+func validateFPELength(radix, n int) error {
+	if n < 2 {
+		return errors.New("FPE input must be at least 2 characters")
+	}
+	domain := new(big.Int).Exp(big.NewInt(int64(radix)), big.NewInt(int64(n)), nil)
+	if domain.Cmp(big.NewInt(fpeMinDomain)) < 0 {
+		return errors.New("FPE input too short: radix^length must be at least 1,000,000")
+	}
+	return nil
+}
+
+// fpeNum returns NUM_radix(x), the integer whose base-radix digits are x
+// (most significant first).
+func fpeNum(x []uint16, radix int) *big.Int {
+	n := new(big.Int)
+	r := big.NewInt(int64(radix))
+	d := new(big.Int)
+	for _, digit := range x {
+		n.Mul(n, r)
+		n.Add(n, d.SetUint64(uint64(digit)))
+	}
+	return n
+}
+
+// fpeStr returns STR^m_radix(n), the m-digit base-radix representation of n.
+func fpeStr(n *big.Int, radix, m int) []uint16 {
+	out := make([]uint16, m)
+	q := new(big.Int).Set(n)
+	r := big.NewInt(int64(radix))
+	rem := new(big.Int)
+	for i := m - 1; i >= 0; i-- {
+		q.QuoRem(q, r, rem)
+		out[i] = uint16(rem.Uint64())
+	}
+	return out
+}
+
+// fpeRev returns a reversed copy of x.
+func fpeRev(x []uint16) []uint16 {
+	out := slices.Clone(x)
+	slices.Reverse(out)
+	return out
+}
+
+// fpeAlphabet maps between characters and numerals.
+type fpeAlphabet struct {
+	chars []rune
+	index map[rune]uint16
+}
+
+// newFPEAlphabet validates alphabet as a set of 2 to 65536 distinct characters.
+func newFPEAlphabet(alphabet string) (*fpeAlphabet, error) {
+	chars := []rune(alphabet)
+	if len(chars) < 2 || len(chars) > 1<<16 {
+		return nil, errors.New("invalid FPE alphabet: must have 2 to 65536 characters")
+	}
+
+	index := make(map[rune]uint16, len(chars))
+	for i, r := range chars {
+		if _, dup := index[r]; dup {
+			return nil, errors.New("invalid FPE alphabet: characters must be distinct")
+		}
+		index[r] = uint16(i)
+	}
+	return &fpeAlphabet{chars: chars, index: index}, nil
+}
+
+func (a *fpeAlphabet) radix() int { return len(a.chars) }
+
+// numerals converts s into numerals, rejecting characters outside the alphabet.
+func (a *fpeAlphabet) numerals(s string) ([]uint16, error) {
+	out := make([]uint16, 0, len(s))
+	for _, r := range s {
+		n, ok := a.index[r]
+		if !ok {
+			return nil, errors.New("FPE input contains a character outside the alphabet")
+		}
+		out = append(out, n)
+	}
+	return out, nil
+}
+
+// format converts numerals back into a string.
+func (a *fpeAlphabet) format(x []uint16) string {
+	out := make([]rune, len(x))
+	for i, n := range x {
+		out[i] = a.chars[n]
+	}
+	return string(out)
+}
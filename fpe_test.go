@@ -0,0 +1,169 @@
+package goaes_test
+
+import (
+	"strings"
+	"testing"
+
+	goaes "github.com/fawwazid/go-aes"
+)
+
+// TestFF1_NISTSamples checks the NIST SP 800-38G FF1 sample vectors.
+func TestFF1_NISTSamples(t *testing.T) {
+	const (
+		key128 = "2b7e151628aed2a6abf7158809cf4f3c"
+		key192 = "2b7e151628aed2a6abf7158809cf4f3cef4359d8d580aa4f"
+		key256 = "2b7e151628aed2a6abf7158809cf4f3cef4359d8d580aa4f7f036d6f04fc6a94"
+	)
+	tests := []struct {
+		name, key, tweak, alphabet, plaintext, ciphertext string
+	}{
+		{"Sample 1", key128, "", goaes.AlphabetDigits, "0123456789", "2433477484"},
+		{"Sample 2", key128, "39383736353433323130", goaes.AlphabetDigits, "0123456789", "6124200773"},
+		{"Sample 3", key128, "3737373770717273373737", goaes.AlphabetLowerAlphanumeric, "0123456789abcdefghi", "a9tv40mll9kdu509eum"},
+		{"Sample 4", key192, "", goaes.AlphabetDigits, "0123456789", "2830668132"},
+		{"Sample 5", key192, "39383736353433323130", goaes.AlphabetDigits, "0123456789", "2496655549"},
+		{"Sample 6", key192, "3737373770717273373737", goaes.AlphabetLowerAlphanumeric, "0123456789abcdefghi", "xbj3kv35jrawxv32ysr"},
+		{"Sample 7", key256, "", goaes.AlphabetDigits, "0123456789", "6657667009"},
+		{"Sample 8", key256, "39383736353433323130", goaes.AlphabetDigits, "0123456789", "1001623463"},
+		{"Sample 9", key256, "3737373770717273373737", goaes.AlphabetLowerAlphanumeric, "0123456789abcdefghi", "xs8a0azh2avyalyzuwd"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key := mustHex(t, tt.key)
+			tweak := mustHex(t, tt.tweak)
+
+			ct, err := goaes.EncryptFF1(key, tweak, tt.alphabet, tt.plaintext)
+			if err != nil {
+				t.Fatalf("encrypt failed: %v", err)
+			}
+			if ct != tt.ciphertext {
+				t.Fatalf("ciphertext = %s, want %s", ct, tt.ciphertext)
+			}
+
+			pt, err := goaes.DecryptFF1(key, tweak, tt.alphabet, ct)
+			if err != nil {
+				t.Fatalf("decrypt failed: %v", err)
+			}
+			if pt != tt.plaintext {
+				t.Fatalf("plaintext = %s, want %s", pt, tt.plaintext)
+			}
+		})
+	}
+}
+
+// TestFF31_Sample checks an AES-128 radix-10 FF3-1 sample vector.
+func TestFF31_Sample(t *testing.T) {
+	key := mustHex(t, "2de79d232df5585d68ce47882ae256d6")
+	tweak := mustHex(t, "cbd09280979564")
+
+	ct, err := goaes.EncryptFF31(key, tweak, goaes.AlphabetDigits, "3992520240")
+	if err != nil {
+		t.Fatalf("encrypt failed: %v", err)
+	}
+	if ct != "8901801106" {
+		t.Fatalf("ciphertext = %s, want 8901801106", ct)
+	}
+
+	pt, err := goaes.DecryptFF31(key, tweak, goaes.AlphabetDigits, ct)
+	if err != nil {
+		t.Fatalf("decrypt failed: %v", err)
+	}
+	if pt != "3992520240" {
+		t.Fatalf("plaintext = %s, want 3992520240", pt)
+	}
+}
+
+func TestFPE_EncryptDecrypt(t *testing.T) {
+	tests := []struct {
+		name, alphabet, plaintext string
+	}{
+		{"card number", goaes.AlphabetDigits, "4111111111111111"},
+		{"ssn", goaes.AlphabetDigits, "123456789"},
+		{"alphanumeric", goaes.AlphabetAlphanumeric, "AbC123xyz"},
+		{"custom runes", "αβγδεζηθικλμ", "αβγδεζηθ"},
+	}
+
+	for _, k := range []int{16, 24, 32} {
+		key := make([]byte, k)
+		for i := 0; i < k; i++ {
+			key[i] = byte(i)
+		}
+		tweak := []byte("tweak-7")
+
+		for _, tt := range tests {
+			for _, mode := range []struct {
+				name    string
+				encrypt func(key, tweak []byte, alphabet, plaintext string) (string, error)
+				decrypt func(key, tweak []byte, alphabet, ciphertext string) (string, error)
+			}{
+				{"FF1", goaes.EncryptFF1, goaes.DecryptFF1},
+				{"FF3-1", goaes.EncryptFF31, goaes.DecryptFF31},
+			} {
+				ct, err := mode.encrypt(key, tweak, tt.alphabet, tt.plaintext)
+				if err != nil {
+					t.Fatalf("%s %s encrypt failed for key len %d: %v", mode.name, tt.name, k, err)
+				}
+				if len([]rune(ct)) != len([]rune(tt.plaintext)) {
+					t.Fatalf("%s %s: length not preserved: %q", mode.name, tt.name, ct)
+				}
+				for _, r := range ct {
+					if !strings.ContainsRune(tt.alphabet, r) {
+						t.Fatalf("%s %s: ciphertext %q leaves the alphabet", mode.name, tt.name, ct)
+					}
+				}
+
+				pt, err := mode.decrypt(key, tweak, tt.alphabet, ct)
+				if err != nil {
+					t.Fatalf("%s %s decrypt failed for key len %d: %v", mode.name, tt.name, k, err)
+				}
+				if pt != tt.plaintext {
+					t.Fatalf("%s %s: plaintext mismatch for key len %d", mode.name, tt.name, k)
+				}
+
+				// a different tweak gives a different ciphertext
+				ct2, err := mode.encrypt(key, []byte("other-t"), tt.alphabet, tt.plaintext)
+				if err != nil {
+					t.Fatalf("%s %s encrypt failed: %v", mode.name, tt.name, err)
+				}
+				if ct2 == ct {
+					t.Fatalf("%s %s: expected tweak to change ciphertext", mode.name, tt.name)
+				}
+			}
+		}
+	}
+}
+
+func TestFPE_InvalidInput(t *testing.T) {
+	key := make([]byte, 16)
+	tweak := make([]byte, 7)
+
+	if _, err := goaes.EncryptFF1([]byte("invalid-key"), nil, goaes.AlphabetDigits, "0123456789"); err == nil {
+		t.Error("expected error for invalid key size in EncryptFF1")
+	}
+	if _, err := goaes.EncryptFF31([]byte("invalid-key"), tweak, goaes.AlphabetDigits, "0123456789"); err == nil {
+		t.Error("expected error for invalid key size in EncryptFF31")
+	}
+	if _, err := goaes.EncryptFF31(key, make([]byte, 8), goaes.AlphabetDigits, "0123456789"); err == nil {
+		t.Error("expected error for 8-byte FF3-1 tweak")
+	}
+	if _, err := goaes.EncryptFF1(key, nil, goaes.AlphabetDigits, "12345"); err == nil {
+		t.Error("expected error for domain smaller than one million")
+	}
+	if _, err := goaes.EncryptFF1(key, nil, goaes.AlphabetDigits, "12345x7890"); err == nil {
+		t.Error("expected error for character outside the alphabet")
+	}
+	if _, err := goaes.EncryptFF1(key, nil, "0", "0000000"); err == nil {
+		t.Error("expected error for single-character alphabet")
+	}
+	if _, err := goaes.EncryptFF1(key, nil, "0120", "0120120"); err == nil {
+		t.Error("expected error for alphabet with duplicates")
+	}
+	long := make([]byte, 57)
+	for i := range long {
+		long[i] = '1'
+	}
+	if _, err := goaes.EncryptFF31(key, tweak, goaes.AlphabetDigits, string(long)); err == nil {
+		t.Error("expected error for FF3-1 input longer than 56 digits")
+	}
+}
@@ -2,11 +2,12 @@ package goaes
 
 import (
 	"crypto/cipher"
-	"crypto/rand"
 	"errors"
-	"io"
 )
 
+// gcmStandardNonceSize is the 96-bit nonce length recommended by SP 800-38D.
+const gcmStandardNonceSize = 12
+
 // EncryptGCM encrypts plaintext using AES-GCM (Galois/Counter Mode).
 //
 // NIST SP 800-38D Recommendation: Authenticated Encryption (AEAD).
@@ -17,62 +18,188 @@ import (
 //   - plaintext: Data to be encrypted.
 //   - aad: Additional Authenticated Data (optional, can be nil). PROOF of integrity, not encrypted.
 //
+// Calls are served from the key cache when enabled with SetKeyCacheSize.
+//
 // Returns: nonce||ciphertext
 func EncryptGCM(key, plaintext, aad []byte) ([]byte, error) {
-	block, err := newCipherBlock(key)
+	return SealGCM(nil, key, plaintext, aad)
+}
+
+// DecryptGCM decrypts data produced by EncryptGCM.
+// It expects the nonce to be prepended to the ciphertext.
+//
+// Parameters:
+//   - key: same key used for encryption.
+//   - ciphertext: nonce||ciphertext.
+//   - aad: same additional data used for encryption.
+//
+// Returns: decrypted plaintext.
+func DecryptGCM(key, ciphertext, aad []byte) ([]byte, error) {
+	return OpenGCM(nil, key, ciphertext, aad)
+}
+
+// SealGCM is the append-style form of EncryptGCM: it appends
+// nonce||ciphertext to dst and returns the updated slice. When dst has
+// enough spare capacity (len(plaintext)+28 bytes) and the key cache is
+// enabled, it does not allocate. dst may overlap plaintext, e.g. buf[:0] to
+// encrypt buf in place; aad must not overlap dst.
+func SealGCM(dst, key, plaintext, aad []byte) ([]byte, error) {
+	gcm, err := gcmForKey(key)
 	if err != nil {
 		return nil, err
 	}
+	return sealPrefixed(dst, gcm, plaintext, aad)
+}
 
-	gcm, err := cipher.NewGCM(block)
+// OpenGCM is the append-style form of DecryptGCM: it appends the plaintext
+// to dst and returns the updated slice. When dst has enough spare capacity
+// (len(ciphertext)-12 bytes) and the key cache is enabled, it does not
+// allocate. dst may overlap ciphertext, e.g. buf[:0] to decrypt buf in
+// place; on failure the overlapping bytes are unspecified.
+func OpenGCM(dst, key, ciphertext, aad []byte) ([]byte, error) {
+	gcm, err := gcmForKey(key)
 	if err != nil {
 		return nil, err
 	}
+	return openPrefixed(dst, gcm, ciphertext, aad)
+}
 
-	nonce := make([]byte, gcm.NonceSize())
-	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+// EncryptGCMWithNonce encrypts plaintext using AES-GCM with a caller-supplied
+// nonce, for protocols that transmit the nonce separately.
+//
+// NIST SP 800-38D Warning: A nonce must NEVER be reused with the same key;
+// doing so reveals the authentication key and breaks confidentiality.
+//
+// Parameters:
+//   - key: 16/24/32 bytes (AES-128/192/256).
+//   - nonce: at least 12 bytes. 12 bytes (96 bits) is recommended.
+//   - plaintext: Data to be encrypted.
+//   - aad: Additional Authenticated Data (optional, can be nil).
+//   - tagSize: 12 to 16 bytes. Tags shorter than 16 bytes require a 12-byte nonce.
+//
+// Returns: ciphertext||tag (the nonce is not included).
+func EncryptGCMWithNonce(key, nonce, plaintext, aad []byte, tagSize int) ([]byte, error) {
+	gcm, err := NewGCMWithSizes(key, len(nonce), tagSize)
+	if err != nil {
 		return nil, err
 	}
+	return gcm.Seal(nil, nonce, plaintext, aad), nil
+}
 
-	ct := gcm.Seal(nil, nonce, plaintext, aad)
+// DecryptGCMWithNonce decrypts data produced by EncryptGCMWithNonce.
+//
+// Parameters:
+//   - key: same key used for encryption.
+//   - nonce: same nonce used for encryption.
+//   - ciphertext: ciphertext||tag.
+//   - aad: same additional data used for encryption.
+//   - tagSize: same value used for encryption.
+//
+// Returns: decrypted plaintext.
+func DecryptGCMWithNonce(key, nonce, ciphertext, aad []byte, tagSize int) ([]byte, error) {
+	gcm, err := NewGCMWithSizes(key, len(nonce), tagSize)
+	if err != nil {
+		return nil, err
+	}
 
-	out := make([]byte, 0, len(nonce)+len(ct))
-	out = append(out, nonce...)
-	out = append(out, ct...)
-	return out, nil
+	pt, err := gcm.Open(nil, nonce, ciphertext, aad)
+	if err != nil {
+		return nil, err
+	}
+	return pt, nil
 }
 
-// DecryptGCM decrypts data produced by EncryptGCM.
+// EncryptGCMWithSizes is EncryptGCM with a configurable random nonce size and
+// tag size, for interoperating with systems that use e.g. 16-byte nonces or
+// 12-byte tags.
+//
+// Parameters:
+//   - key: 16/24/32 bytes (AES-128/192/256).
+//   - plaintext: Data to be encrypted.
+//   - aad: Additional Authenticated Data (optional, can be nil).
+//   - nonceSize: at least 12 bytes. 12 bytes (96 bits) is recommended.
+//   - tagSize: 12 to 16 bytes. Tags shorter than 16 bytes require a 12-byte nonce.
+//
+// Returns: nonce||ciphertext
+func EncryptGCMWithSizes(key, plaintext, aad []byte, nonceSize, tagSize int) ([]byte, error) {
+	gcm, err := NewGCMWithSizes(key, nonceSize, tagSize)
+	if err != nil {
+		return nil, err
+	}
+	return sealPrefixed(nil, gcm, plaintext, aad)
+}
+
+// DecryptGCMWithSizes decrypts data produced by EncryptGCMWithSizes.
 // It expects the nonce to be prepended to the ciphertext.
 //
 // Parameters:
 //   - key: same key used for encryption.
 //   - ciphertext: nonce||ciphertext.
 //   - aad: same additional data used for encryption.
+//   - nonceSize, tagSize: same values used for encryption.
 //
 // Returns: decrypted plaintext.
-func DecryptGCM(key, ciphertext, aad []byte) ([]byte, error) {
-	block, err := newCipherBlock(key)
+func DecryptGCMWithSizes(key, ciphertext, aad []byte, nonceSize, tagSize int) ([]byte, error) {
+	gcm, err := NewGCMWithSizes(key, nonceSize, tagSize)
 	if err != nil {
 		return nil, err
 	}
+	return openPrefixed(nil, gcm, ciphertext, aad)
+}
 
-	gcm, err := cipher.NewGCM(block)
-	if err != nil {
+// NewGCM returns AES-GCM with a 12-byte nonce and 16-byte tag as a
+// cipher.AEAD, for use wherever cipher.NewGCM would be used. The one-shot
+// GCM functions produce the same ciphertexts.
+//
+// NIST SP 800-38D Warning: A nonce must NEVER be reused with the same key.
+// With random nonces, encrypt at most 2^32 messages per key (see KeyHandle).
+//
+// Parameters:
+//   - key: 16/24/32 bytes (AES-128/192/256).
+func NewGCM(key []byte) (cipher.AEAD, error) {
+	return newGCM(key, gcmStandardNonceSize, 16)
+}
+
+// NewGCMWithSizes is NewGCM with a configurable nonce size and tag size.
+//
+// Parameters:
+//   - key: 16/24/32 bytes (AES-128/192/256).
+//   - nonceSize: at least 12 bytes. 12 bytes (96 bits) is recommended.
+//   - tagSize: 12 to 16 bytes. Tags shorter than 16 bytes require a 12-byte nonce.
+func NewGCMWithSizes(key []byte, nonceSize, tagSize int) (cipher.AEAD, error) {
+	return newGCM(key, nonceSize, tagSize)
+}
+
+// newGCM validates the key, nonce size and tag size and returns AES-GCM.
+func newGCM(key []byte, nonceSize, tagSize int) (cipher.AEAD, error) {
+	if err := validateGCMSizes(nonceSize, tagSize); err != nil {
 		return nil, err
 	}
 
-	nonceSize := gcm.NonceSize()
-	if len(ciphertext) < nonceSize {
-		return nil, errors.New("ciphertext too short")
+	block, err := newCipherBlock(key)
+	if err != nil {
+		return nil, err
 	}
 
-	nonce := ciphertext[:nonceSize]
-	ct := ciphertext[nonceSize:]
+	if nonceSize != gcmStandardNonceSize {
+		return cipher.NewGCMWithNonceSize(block, nonceSize)
+	}
+	return cipher.NewGCMWithTagSize(block, tagSize)
+}
 
-	pt, err := gcm.Open(nil, nonce, ct, aad)
-	if err != nil {
-		return nil, err
+// validateGCMSizes enforces the SP 800-38D recommendations: nonces of at least
+// 96 bits and tags of 96 to 128 bits. The 32- and 64-bit tags of Appendix C
+// are rejected, as are truncated tags with non-96-bit nonces, whose counter
+// blocks are derived through GHASH.
+func validateGCMSizes(nonceSize, tagSize int) error {
+	if nonceSize < gcmStandardNonceSize {
+		return errors.New("invalid GCM nonce size: must be at least 12 bytes")
 	}
-	return pt, nil
+	if tagSize < 12 || tagSize > 16 {
+		return errors.New("invalid GCM tag size: must be 12 to 16 bytes")
+	}
+	if nonceSize != gcmStandardNonceSize && tagSize != 16 {
+		return errors.New("invalid GCM parameters: truncated tags require a 12-byte nonce")
+	}
+	return nil
 }
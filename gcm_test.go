@@ -55,3 +55,140 @@ func TestAESGCM_InvalidKey(t *testing.T) {
 		t.Error("expected error for invalid key size in DecryptGCM")
 	}
 }
+
+// TestAESGCM_WithNonceVector checks GCM test case 4 from the McGrew-Viega
+// GCM specification, with the full and a truncated 96-bit tag.
+func TestAESGCM_WithNonceVector(t *testing.T) {
+	key := mustHex(t, "feffe9928665731c6d6a8f9467308308")
+	nonce := mustHex(t, "cafebabefacedbaddecaf888")
+	plaintext := mustHex(t, "d9313225f88406e5a55909c5aff5269a86a7a9531534f7da2e4c303d8a318a721c3c0c95956809532fcf0e2449a6b525b16aedf5aa0de657ba637b39")
+	aad := mustHex(t, "feedfacedeadbeeffeedfacedeadbeefabaddad2")
+	ciphertext := mustHex(t, "42831ec2217774244b7221b784d0d49ce3aa212f2c02a4e035c17e2329aca12e21d514b25466931c7d8f6a5aac84aa051ba30b396a0aac973d58e091")
+	tag := mustHex(t, "5bc94fbc3221a5db94fae95ae7121a47")
+
+	for _, tagSize := range []int{16, 12} {
+		want := append(append([]byte{}, ciphertext...), tag[:tagSize]...)
+
+		ct, err := goaes.EncryptGCMWithNonce(key, nonce, plaintext, aad, tagSize)
+		if err != nil {
+			t.Fatalf("encrypt failed for tag size %d: %v", tagSize, err)
+		}
+		if !bytes.Equal(ct, want) {
+			t.Fatalf("tag size %d: ciphertext = %x, want %x", tagSize, ct, want)
+		}
+
+		pt, err := goaes.DecryptGCMWithNonce(key, nonce, ct, aad, tagSize)
+		if err != nil {
+			t.Fatalf("decrypt failed for tag size %d: %v", tagSize, err)
+		}
+		if !bytes.Equal(pt, plaintext) {
+			t.Fatalf("tag size %d: plaintext mismatch", tagSize)
+		}
+
+		bad := make([]byte, len(ct))
+		copy(bad, ct)
+		bad[len(bad)-1] ^= 0xFF
+		if _, err := goaes.DecryptGCMWithNonce(key, nonce, bad, aad, tagSize); err == nil {
+			t.Fatalf("tag size %d: expected error for tampered tag", tagSize)
+		}
+	}
+}
+
+func TestAESGCM_WithSizes(t *testing.T) {
+	plaintext := []byte("The quick brown fox jumps over the lazy dog")
+	aad := []byte("header-aad")
+	key := make([]byte, 32)
+
+	for _, p := range []struct{ nonce, tag int }{{12, 16}, {12, 12}, {12, 14}, {16, 16}, {32, 16}} {
+		ct, err := goaes.EncryptGCMWithSizes(key, plaintext, aad, p.nonce, p.tag)
+		if err != nil {
+			t.Fatalf("encrypt failed (%+v): %v", p, err)
+		}
+		if len(ct) != p.nonce+len(plaintext)+p.tag {
+			t.Fatalf("unexpected ciphertext length %d (%+v)", len(ct), p)
+		}
+
+		pt, err := goaes.DecryptGCMWithSizes(key, ct, aad, p.nonce, p.tag)
+		if err != nil {
+			t.Fatalf("decrypt failed (%+v): %v", p, err)
+		}
+		if !bytes.Equal(pt, plaintext) {
+			t.Fatalf("plaintext mismatch (%+v)", p)
+		}
+
+		if p.nonce == 12 && p.tag == 16 {
+			if _, err := goaes.DecryptGCM(key, ct, aad); err != nil {
+				t.Fatalf("default sizes should match DecryptGCM: %v", err)
+			}
+		}
+	}
+}
+
+func TestAESGCM_InvalidSizes(t *testing.T) {
+	key := make([]byte, 32)
+
+	for _, p := range []struct{ nonce, tag int }{
+		{8, 16},  // nonce below 96 bits
+		{12, 8},  // 64-bit tag (SP 800-38D Appendix C)
+		{12, 4},  // 32-bit tag
+		{12, 17}, // tag longer than the block
+		{16, 12}, // truncated tag with non-96-bit nonce
+	} {
+		if _, err := goaes.EncryptGCMWithSizes(key, []byte("secret"), nil, p.nonce, p.tag); err == nil {
+			t.Errorf("expected error in EncryptGCMWithSizes (%+v)", p)
+		}
+		if _, err := goaes.DecryptGCMWithSizes(key, make([]byte, 64), nil, p.nonce, p.tag); err == nil {
+			t.Errorf("expected error in DecryptGCMWithSizes (%+v)", p)
+		}
+		if _, err := goaes.EncryptGCMWithNonce(key, make([]byte, p.nonce), []byte("secret"), nil, p.tag); err == nil {
+			t.Errorf("expected error in EncryptGCMWithNonce (%+v)", p)
+		}
+	}
+
+	if _, err := goaes.DecryptGCMWithSizes(key, make([]byte, 15), nil, 16, 16); err == nil {
+		t.Error("expected error for short ciphertext in DecryptGCMWithSizes")
+	}
+}
+
+func TestAESGCM_NewGCM(t *testing.T) {
+	key := bytes.Repeat([]byte{0x07}, 32)
+	plaintext := []byte("reusable AEAD object")
+	aad := []byte("header-aad")
+
+	aead, err := goaes.NewGCM(key)
+	if err != nil {
+		t.Fatalf("NewGCM failed: %v", err)
+	}
+	if aead.NonceSize() != 12 || aead.Overhead() != 16 {
+		t.Fatalf("NonceSize/Overhead = %d/%d, want 12/16", aead.NonceSize(), aead.Overhead())
+	}
+
+	// nonce||Seal output is the EncryptGCM format, and vice versa.
+	nonce, err := goaes.GenerateNonce(aead.NonceSize())
+	if err != nil {
+		t.Fatalf("GenerateNonce failed: %v", err)
+	}
+	pt, err := goaes.DecryptGCM(key, aead.Seal(nonce, nonce, plaintext, aad), aad)
+	if err != nil || !bytes.Equal(pt, plaintext) {
+		t.Fatalf("DecryptGCM of Seal output = %q, %v", pt, err)
+	}
+
+	ct, err := goaes.EncryptGCM(key, plaintext, aad)
+	if err != nil {
+		t.Fatalf("EncryptGCM failed: %v", err)
+	}
+	pt, err = aead.Open(nil, ct[:12], ct[12:], aad)
+	if err != nil || !bytes.Equal(pt, plaintext) {
+		t.Fatalf("Open of EncryptGCM output = %q, %v", pt, err)
+	}
+
+	if _, err := goaes.NewGCM(make([]byte, 10)); err == nil {
+		t.Fatal("expected error for invalid key size")
+	}
+	if _, err := goaes.NewGCMWithSizes(key, 12, 8); err == nil {
+		t.Fatal("expected error for 8-byte tag")
+	}
+	if _, err := goaes.NewGCMWithSizes(key, 8, 16); err == nil {
+		t.Fatal("expected error for 8-byte nonce")
+	}
+}
@@ -0,0 +1,139 @@
+package goaes
+
+import (
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"io"
+)
+
+const (
+	// gcmCommitSaltSize is the random per-message HKDF salt length.
+	gcmCommitSaltSize = 32
+	// gcmCommitSize is the length of the key commitment.
+	gcmCommitSize = sha256.Size
+)
+
+// EncryptGCMCommitting encrypts plaintext using AES-GCM with a key commitment.
+//
+// Plain GCM is not key-committing: a crafted ciphertext can decrypt validly
+// under more than one key, which enables partitioning-oracle attacks on
+// multi-recipient and password-based schemes. Here a per-message encryption
+// key and a 32-byte commitment are derived from key and a random salt with
+// HKDF-SHA256; the commitment is stored in the clear and checked before
+// decryption, so a ciphertext only opens under the key that produced it.
+//
+// Parameters:
+//   - key: 16/24/32 bytes (AES-128/192/256) master key.
+//   - plaintext: Data to be encrypted.
+//   - aad: Additional Authenticated Data (optional, can be nil).
+//
+// Returns: salt||commitment||nonce||ciphertext
+func EncryptGCMCommitting(key, plaintext, aad []byte) ([]byte, error) {
+	return SealGCMCommitting(nil, key, plaintext, aad)
+}
+
+// DecryptGCMCommitting decrypts data produced by EncryptGCMCommitting.
+// The commitment is verified before the GCM tag, and any mismatch returns
+// the same authentication error.
+//
+// Parameters:
+//   - key: same master key used for encryption.
+//   - ciphertext: salt||commitment||nonce||ciphertext.
+//   - aad: same additional data used for encryption.
+//
+// Returns: decrypted plaintext.
+func DecryptGCMCommitting(key, ciphertext, aad []byte) ([]byte, error) {
+	return OpenGCMCommitting(nil, key, ciphertext, aad)
+}
+
+// SealGCMCommitting is the append-style form of EncryptGCMCommitting: it
+// appends salt||commitment||nonce||ciphertext to dst and returns the updated
+// slice. dst may overlap plaintext, e.g. buf[:0] to encrypt buf in place;
+// aad must not overlap dst.
+func SealGCMCommitting(dst, key, plaintext, aad []byte) ([]byte, error) {
+	if err := validateKeySize(key); err != nil {
+		return nil, err
+	}
+
+	const header = gcmCommitSaltSize + gcmCommitSize
+	ret, out := sliceForAppend(dst, header+gcmStandardNonceSize+len(plaintext)+16)
+	if anyOverlap(out, plaintext) {
+		body := out[header+gcmStandardNonceSize:]
+		plaintext = body[:copy(body, plaintext)]
+	}
+
+	salt := out[:gcmCommitSaltSize]
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, err
+	}
+
+	encKey, commitment, err := gcmCommitKeys(key, salt)
+	if err != nil {
+		return nil, err
+	}
+	defer clear(encKey)
+	copy(out[gcmCommitSaltSize:], commitment)
+
+	// Per-message keys bypass the key cache, which would only churn.
+	gcm, err := NewGCM(encKey)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := sealPrefixed(out[header:header], gcm, plaintext, aad); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// OpenGCMCommitting is the append-style form of DecryptGCMCommitting: it
+// appends the plaintext to dst and returns the updated slice. dst may
+// overlap ciphertext, e.g. buf[:0] to decrypt buf in place.
+func OpenGCMCommitting(dst, key, ciphertext, aad []byte) ([]byte, error) {
+	if err := validateKeySize(key); err != nil {
+		return nil, err
+	}
+
+	if len(ciphertext) < gcmCommitSaltSize+gcmCommitSize {
+		return nil, errors.New("ciphertext too short")
+	}
+
+	salt := ciphertext[:gcmCommitSaltSize]
+	commitment := ciphertext[gcmCommitSaltSize : gcmCommitSaltSize+gcmCommitSize]
+	ct := ciphertext[gcmCommitSaltSize+gcmCommitSize:]
+
+	encKey, expected, err := gcmCommitKeys(key, salt)
+	if err != nil {
+		return nil, err
+	}
+	defer clear(encKey)
+
+	if subtle.ConstantTimeCompare(expected, commitment) != 1 {
+		return nil, errOpen
+	}
+
+	gcm, err := NewGCM(encKey)
+	if err != nil {
+		return nil, err
+	}
+	return openPrefixed(dst, gcm, ct, aad)
+}
+
+// gcmCommitKeys derives the per-message AES key and the key commitment from
+// the master key and salt. HKDF-SHA256 is collision resistant, so no two keys
+// yield the same commitment for a given salt.
+func gcmCommitKeys(key, salt []byte) ([]byte, []byte, error) {
+	encKey, err := hkdf.Key(sha256.New, key, salt, "goaes gcm-committing encryption", len(key))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	commitment, err := hkdf.Key(sha256.New, key, salt, "goaes gcm-committing commitment", gcmCommitSize)
+	if err != nil {
+		clear(encKey)
+		return nil, nil, err
+	}
+	return encKey, commitment, nil
+}
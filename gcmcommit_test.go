@@ -0,0 +1,83 @@
+package goaes_test
+
+import (
+	"bytes"
+	"testing"
+
+	goaes "github.com/fawwazid/go-aes"
+)
+
+func TestGCMCommitting_EncryptDecrypt(t *testing.T) {
+	plaintext := []byte("One ciphertext, exactly one key")
+	aad := []byte("header-aad")
+
+	for _, k := range []int{16, 24, 32} {
+		key := make([]byte, k)
+		for i := 0; i < k; i++ {
+			key[i] = byte(i)
+		}
+
+		ct, err := goaes.EncryptGCMCommitting(key, plaintext, aad)
+		if err != nil {
+			t.Fatalf("encrypt failed for key len %d: %v", k, err)
+		}
+		if want := 32 + 32 + 12 + len(plaintext) + 16; len(ct) != want {
+			t.Fatalf("ciphertext len = %d, want %d", len(ct), want)
+		}
+
+		pt, err := goaes.DecryptGCMCommitting(key, ct, aad)
+		if err != nil {
+			t.Fatalf("decrypt failed for key len %d: %v", k, err)
+		}
+		if !bytes.Equal(pt, plaintext) {
+			t.Fatalf("plaintext mismatch for key len %d", k)
+		}
+
+		// tamper detection: salt, commitment, nonce and tag
+		for _, pos := range []int{0, 32, 64, len(ct) - 1} {
+			bad := make([]byte, len(ct))
+			copy(bad, ct)
+			bad[pos] ^= 0xFF
+			if _, err := goaes.DecryptGCMCommitting(key, bad, aad); err == nil {
+				t.Fatalf("expected error for tampered byte %d (key len %d)", pos, k)
+			}
+		}
+
+		if _, err := goaes.DecryptGCMCommitting(key, ct, []byte("other-aad")); err == nil {
+			t.Fatalf("expected error for wrong aad (key len %d)", k)
+		}
+	}
+}
+
+func TestGCMCommitting_WrongKey(t *testing.T) {
+	key1 := bytes.Repeat([]byte{0x01}, 32)
+	key2 := bytes.Repeat([]byte{0x02}, 32)
+
+	ct, err := goaes.EncryptGCMCommitting(key1, []byte("secret"), nil)
+	if err != nil {
+		t.Fatalf("encrypt failed: %v", err)
+	}
+	if _, err := goaes.DecryptGCMCommitting(key2, ct, nil); err == nil {
+		t.Fatal("expected error when decrypting under a different key")
+	}
+	if _, err := goaes.DecryptGCMCommitting(key1[:16], ct, nil); err == nil {
+		t.Fatal("expected error when decrypting under a shorter key")
+	}
+
+	// The commitment is not a plain GCM ciphertext.
+	if _, err := goaes.DecryptGCM(key1, ct, nil); err == nil {
+		t.Fatal("expected DecryptGCM to reject a committing ciphertext")
+	}
+}
+
+func TestGCMCommitting_InvalidInput(t *testing.T) {
+	if _, err := goaes.EncryptGCMCommitting([]byte("invalid-key"), []byte("secret"), nil); err == nil {
+		t.Error("expected error for invalid key size in EncryptGCMCommitting")
+	}
+	if _, err := goaes.DecryptGCMCommitting([]byte("invalid-key"), make([]byte, 100), nil); err == nil {
+		t.Error("expected error for invalid key size in DecryptGCMCommitting")
+	}
+	if _, err := goaes.DecryptGCMCommitting(make([]byte, 32), make([]byte, 63), nil); err == nil {
+		t.Error("expected error for short ciphertext in DecryptGCMCommitting")
+	}
+}
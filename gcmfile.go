@@ -0,0 +1,198 @@
+package goaes
+
+import (
+	"crypto/cipher"
+	"errors"
+	"io"
+	"sync"
+)
+
+// GCMFileReader provides random access to the plaintext of a stream written
+// by NewGCMEncryptWriter, such as an encrypted file. It implements
+// io.Reader, io.ReaderAt and io.Seeker.
+//
+// Only the segments that overlap a requested range are read and
+// authenticated, so any byte range can be read without decrypting from the
+// start. Plaintext is never returned from a segment that fails
+// authentication. The final segment is verified when the reader is created,
+// which detects truncated or extended files and makes Size trustworthy.
+//
+// It is safe for concurrent use; concurrent calls are serialized.
+type GCMFileReader struct {
+	mu     sync.Mutex
+	r      io.ReaderAt
+	aead   cipher.AEAD
+	aad    []byte
+	nseg   int64 // number of segments, at least 1
+	size   int64 // plaintext size
+	ctSize int64 // size of the segments, excluding the salt
+	offset int64 // position for Read and Seek
+
+	buf    []byte // ciphertext and, after opening, plaintext of one segment
+	cached int64  // index of the segment whose plaintext is in pt, or -1
+	pt     []byte
+}
+
+// NewGCMFileReader returns a GCMFileReader for the size-byte stream in r.
+//
+// Parameters:
+//   - r: source of salt||segments, e.g. an *os.File.
+//   - size: total length of the stream in r.
+//   - key: same key used for encryption.
+//   - aad: same additional data used for encryption.
+//
+// Returns an error if the stream is malformed or its final segment does not
+// authenticate (wrong key or aad, truncation, or appended data).
+func NewGCMFileReader(r io.ReaderAt, size int64, key, aad []byte) (*GCMFileReader, error) {
+	const encSegment = gcmStreamSegmentSize + gcmStreamTagSize
+
+	if err := validateKeySize(key); err != nil {
+		return nil, err
+	}
+	if size < gcmStreamSaltSize+gcmStreamTagSize {
+		return nil, errors.New("ciphertext too short")
+	}
+
+	salt := make([]byte, gcmStreamSaltSize)
+	if err := readFullAt(r, salt, 0); err != nil {
+		return nil, err
+	}
+
+	aead, err := gcmStreamAEAD(key, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	ctSize := size - gcmStreamSaltSize
+	nseg := (ctSize + encSegment - 1) / encSegment
+	// Every segment, including the last, carries a full tag.
+	if ctSize-(nseg-1)*encSegment < gcmStreamTagSize {
+		return nil, errOpen
+	}
+
+	f := &GCMFileReader{
+		r:      r,
+		aead:   aead,
+		aad:    append([]byte(nil), aad...),
+		nseg:   nseg,
+		size:   ctSize - nseg*gcmStreamTagSize,
+		ctSize: ctSize,
+		buf:    make([]byte, encSegment),
+		cached: -1,
+	}
+	if _, err := f.segment(nseg - 1); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Size returns the plaintext size.
+func (f *GCMFileReader) Size() int64 {
+	return f.size
+}
+
+// ReadAt reads len(p) plaintext bytes starting at off. As with any
+// io.ReaderAt, it returns an error whenever it reads fewer than len(p)
+// bytes: io.EOF at the end of the plaintext, or an authentication error if
+// an overlapping segment was tampered with. Bytes already copied into p come
+// from authenticated segments only.
+func (f *GCMFileReader) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errors.New("negative offset")
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.readAt(p, off)
+}
+
+// Read reads up to len(p) plaintext bytes from the current offset.
+func (f *GCMFileReader) Read(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.offset >= f.size {
+		return 0, io.EOF
+	}
+	n, err := f.readAt(p[:min(int64(len(p)), f.size-f.offset)], f.offset)
+	f.offset += int64(n)
+	return n, err
+}
+
+// Seek sets the offset for the next Read, as described by io.Seeker.
+// Offsets are in plaintext bytes.
+func (f *GCMFileReader) Seek(offset int64, whence int) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += f.offset
+	case io.SeekEnd:
+		offset += f.size
+	default:
+		return 0, errors.New("invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("negative position")
+	}
+	f.offset = offset
+	return offset, nil
+}
+
+// readAt implements ReadAt. f.mu must be held.
+func (f *GCMFileReader) readAt(p []byte, off int64) (int, error) {
+	n := 0
+	for n < len(p) {
+		pos := off + int64(n)
+		if pos >= f.size {
+			return n, io.EOF
+		}
+
+		pt, err := f.segment(pos / gcmStreamSegmentSize)
+		if err != nil {
+			return n, err
+		}
+		n += copy(p[n:], pt[pos%gcmStreamSegmentSize:])
+	}
+	return n, nil
+}
+
+// segment returns the authenticated plaintext of segment i, reading and
+// opening it unless it is already cached. f.mu must be held, except during
+// construction.
+func (f *GCMFileReader) segment(i int64) ([]byte, error) {
+	const encSegment = gcmStreamSegmentSize + gcmStreamTagSize
+
+	if f.cached == i {
+		return f.pt, nil
+	}
+	f.cached = -1
+
+	start := i * encSegment
+	ct := f.buf[:min(encSegment, f.ctSize-start)]
+	if err := readFullAt(f.r, ct, gcmStreamSaltSize+start); err != nil {
+		return nil, err
+	}
+
+	nonce := gcmStreamNonce(uint64(i), i == f.nseg-1)
+	pt, err := f.aead.Open(ct[:0], nonce[:], ct, f.aad)
+	if err != nil {
+		return nil, errOpen
+	}
+	f.cached, f.pt = i, pt
+	return pt, nil
+}
+
+// readFullAt reads exactly len(p) bytes from r at off.
+func readFullAt(r io.ReaderAt, p []byte, off int64) error {
+	n, err := r.ReadAt(p, off)
+	if n == len(p) {
+		return nil
+	}
+	if err == nil || err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return err
+}
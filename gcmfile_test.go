@@ -0,0 +1,228 @@
+package goaes_test
+
+import (
+	"bytes"
+	"io"
+	"sync"
+	"testing"
+
+	goaes "github.com/fawwazid/go-aes"
+)
+
+// countingReaderAt records the offsets of the reads made through it.
+type countingReaderAt struct {
+	mu   sync.Mutex
+	r    io.ReaderAt
+	offs []int64
+}
+
+func (c *countingReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	c.mu.Lock()
+	c.offs = append(c.offs, off)
+	c.mu.Unlock()
+	return c.r.ReadAt(p, off)
+}
+
+func newFileReader(t *testing.T, ct, key, aad []byte) *goaes.GCMFileReader {
+	t.Helper()
+	f, err := goaes.NewGCMFileReader(bytes.NewReader(ct), int64(len(ct)), key, aad)
+	if err != nil {
+		t.Fatalf("NewGCMFileReader failed: %v", err)
+	}
+	return f
+}
+
+func TestGCMFile_ReadAt(t *testing.T) {
+	key := bytes.Repeat([]byte{0x21}, 32)
+	aad := []byte("media.mp4")
+	plaintext := make([]byte, 4*streamSegmentSize+1234)
+	for i := range plaintext {
+		plaintext[i] = byte(i % 251)
+	}
+	f := newFileReader(t, encryptStream(t, key, aad, plaintext, 1<<20), key, aad)
+
+	if f.Size() != int64(len(plaintext)) {
+		t.Fatalf("Size = %d, want %d", f.Size(), len(plaintext))
+	}
+
+	seg := int64(streamSegmentSize)
+	tests := []struct {
+		name      string
+		off, size int64
+	}{
+		{"start", 0, 100},
+		{"inside segment", seg + 10, 500},
+		{"whole segment", 2 * seg, seg},
+		{"straddles one boundary", seg - 50, 100},
+		{"straddles two boundaries", seg - 1, seg + 2},
+		{"ends at boundary", 3*seg - 20, 20},
+		{"into final segment", 4*seg - 10, 100},
+		{"everything", 0, int64(len(plaintext))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := make([]byte, tt.size)
+			n, err := f.ReadAt(p, tt.off)
+			if err != nil {
+				t.Fatalf("ReadAt failed: %v", err)
+			}
+			if !bytes.Equal(p[:n], plaintext[tt.off:tt.off+tt.size]) {
+				t.Fatal("plaintext mismatch")
+			}
+		})
+	}
+
+	// Reading past the end returns what is there, then io.EOF.
+	p := make([]byte, 100)
+	n, err := f.ReadAt(p, int64(len(plaintext))-30)
+	if n != 30 || err != io.EOF {
+		t.Fatalf("ReadAt at end = %d, %v; want 30, io.EOF", n, err)
+	}
+	if !bytes.Equal(p[:n], plaintext[len(plaintext)-30:]) {
+		t.Fatal("plaintext mismatch at end")
+	}
+	if _, err := f.ReadAt(p, -1); err == nil {
+		t.Fatal("expected error for negative offset")
+	}
+}
+
+func TestGCMFile_SeekRead(t *testing.T) {
+	key := bytes.Repeat([]byte{0x22}, 16)
+	plaintext := bytes.Repeat([]byte("parquet row group "), 20000)
+	f := newFileReader(t, encryptStream(t, key, nil, plaintext, 7777), key, nil)
+
+	off := int64(streamSegmentSize + 17)
+	if pos, err := f.Seek(off, io.SeekStart); err != nil || pos != off {
+		t.Fatalf("Seek = %d, %v", pos, err)
+	}
+	rest, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatalf("ReadAll failed: %v", err)
+	}
+	if !bytes.Equal(rest, plaintext[off:]) {
+		t.Fatal("plaintext mismatch after Seek")
+	}
+
+	if pos, err := f.Seek(-10, io.SeekEnd); err != nil || pos != int64(len(plaintext))-10 {
+		t.Fatalf("Seek from end = %d, %v", pos, err)
+	}
+	if pos, err := f.Seek(-5, io.SeekCurrent); err != nil || pos != int64(len(plaintext))-15 {
+		t.Fatalf("Seek from current = %d, %v", pos, err)
+	}
+	if _, err := f.Seek(-1, io.SeekStart); err == nil {
+		t.Fatal("expected error for negative position")
+	}
+}
+
+func TestGCMFile_OnlyOverlappingSegments(t *testing.T) {
+	key := bytes.Repeat([]byte{0x23}, 32)
+	plaintext := make([]byte, 5*streamSegmentSize)
+	ct := encryptStream(t, key, nil, plaintext, 1<<20)
+
+	src := &countingReaderAt{r: bytes.NewReader(ct)}
+	f, err := goaes.NewGCMFileReader(src, int64(len(ct)), key, nil)
+	if err != nil {
+		t.Fatalf("NewGCMFileReader failed: %v", err)
+	}
+
+	src.offs = nil
+	if _, err := f.ReadAt(make([]byte, 200), 2*streamSegmentSize-100); err != nil {
+		t.Fatalf("ReadAt failed: %v", err)
+	}
+	want := []int64{streamSaltSize + 1*streamEncSegment, streamSaltSize + 2*streamEncSegment}
+	if len(src.offs) != len(want) || src.offs[0] != want[0] || src.offs[1] != want[1] {
+		t.Fatalf("segment reads at %v, want %v", src.offs, want)
+	}
+}
+
+func TestGCMFile_TamperedMiddleSegment(t *testing.T) {
+	key := bytes.Repeat([]byte{0x24}, 32)
+	plaintext := bytes.Repeat([]byte{'m'}, 3*streamSegmentSize+10)
+	ct := encryptStream(t, key, nil, plaintext, 1<<20)
+	ct[streamSaltSize+streamEncSegment+100] ^= 0x80 // segment 1
+
+	f := newFileReader(t, ct, key, nil)
+
+	// Untouched segments still read fine.
+	p := make([]byte, 64)
+	if _, err := f.ReadAt(p, 10); err != nil {
+		t.Fatalf("ReadAt in segment 0 failed: %v", err)
+	}
+	if _, err := f.ReadAt(p, 2*streamSegmentSize+10); err != nil {
+		t.Fatalf("ReadAt in segment 2 failed: %v", err)
+	}
+
+	if _, err := f.ReadAt(p, streamSegmentSize+10); err == nil {
+		t.Fatal("expected error reading tampered segment")
+	}
+
+	// A range straddling into the tampered segment stops at the boundary.
+	p = make([]byte, 100)
+	n, err := f.ReadAt(p, streamSegmentSize-40)
+	if err == nil {
+		t.Fatal("expected error for range straddling tampered segment")
+	}
+	if n != 40 || !bytes.Equal(p[:n], plaintext[streamSegmentSize-40:streamSegmentSize]) {
+		t.Fatalf("released %d bytes before the tampered segment, want 40", n)
+	}
+	if bytes.ContainsRune(p[n:], 'm') {
+		t.Fatal("plaintext from tampered segment was released")
+	}
+}
+
+func TestGCMFile_InvalidFiles(t *testing.T) {
+	key := bytes.Repeat([]byte{0x25}, 32)
+	aad := []byte("aad")
+	ct := encryptStream(t, key, aad, bytes.Repeat([]byte{'x'}, 2*streamSegmentSize+5), 1<<20)
+
+	tests := []struct {
+		name string
+		ct   []byte
+		key  []byte
+		aad  []byte
+	}{
+		{"truncated at segment boundary", ct[:streamSaltSize+2*streamEncSegment], key, aad},
+		{"truncated inside tag", ct[:streamSaltSize+2*streamEncSegment+10], key, aad},
+		{"appended data", append(append([]byte(nil), ct...), 0), key, aad},
+		{"only header", ct[:streamSaltSize], key, aad},
+		{"wrong aad", ct, key, []byte("other")},
+		{"wrong key", ct, bytes.Repeat([]byte{0x26}, 32), aad},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := goaes.NewGCMFileReader(bytes.NewReader(tt.ct), int64(len(tt.ct)), tt.key, tt.aad); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
+
+func TestGCMFile_ConcurrentReadAt(t *testing.T) {
+	key := bytes.Repeat([]byte{0x27}, 32)
+	plaintext := make([]byte, 3*streamSegmentSize)
+	for i := range plaintext {
+		plaintext[i] = byte(i >> 8)
+	}
+	f := newFileReader(t, encryptStream(t, key, nil, plaintext, 1<<20), key, nil)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			p := make([]byte, 1000)
+			for i := 0; i < 20; i++ {
+				off := int64((g*7919 + i*104729) % (len(plaintext) - len(p)))
+				if _, err := f.ReadAt(p, off); err != nil {
+					t.Errorf("ReadAt failed: %v", err)
+					return
+				}
+				if !bytes.Equal(p, plaintext[off:off+int64(len(p))]) {
+					t.Errorf("plaintext mismatch at %d", off)
+					return
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+}
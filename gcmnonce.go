@@ -0,0 +1,230 @@
+package goaes
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"sync"
+)
+
+const (
+	// nonceFixedSize is the length of the fixed field of a deterministic nonce.
+	nonceFixedSize = 4
+	// nonceReserve is how many invocation counter values are persisted ahead
+	// of use, so the store is written once per block rather than per nonce.
+	nonceReserve = 1 << 12
+)
+
+var (
+	// ErrNonceExhausted is returned once a NonceSequence has used every
+	// invocation counter value. The key must be replaced.
+	ErrNonceExhausted = errors.New("nonce sequence exhausted")
+
+	// ErrNonceStateNotFound is returned by NonceStore.Load when no state has
+	// been saved yet.
+	ErrNonceStateNotFound = errors.New("nonce state not found")
+)
+
+// NonceState is the persisted state of a NonceSequence.
+type NonceState struct {
+	// Fixed is the 4-byte fixed field identifying the sender.
+	Fixed []byte
+	// Counter is the lowest invocation counter value that may still be used.
+	Counter uint64
+}
+
+// NonceStore persists NonceSequence state, so a restarted sender resumes
+// past every nonce it may already have handed out.
+type NonceStore interface {
+	// Load returns the saved state, or ErrNonceStateNotFound if there is none.
+	Load() (NonceState, error)
+	// Save records state. It must not return until the state is durable.
+	Save(state NonceState) error
+}
+
+// nonceFixedFields tracks the fixed fields of all open NonceSequences in the
+// process, so no two of them can produce the same nonce.
+var nonceFixedFields = struct {
+	sync.Mutex
+	inUse map[[nonceFixedSize]byte]bool
+}{inUse: make(map[[nonceFixedSize]byte]bool)}
+
+// NonceSequence generates 12-byte GCM nonces with the deterministic
+// construction of NIST SP 800-38D, Section 8.2.1: a 4-byte fixed field
+// followed by an 8-byte big-endian invocation counter.
+//
+// The sequence never wraps; once the counter is used up Next returns
+// ErrNonceExhausted. It is safe for concurrent use.
+//
+// NIST SP 800-38D Warning: Every sender using the same key must have a
+// distinct fixed field. Within a process this is enforced; across processes
+// or devices, assign fixed fields explicitly (e.g. from a device ID).
+type NonceSequence struct {
+	mu       sync.Mutex
+	fixed    [nonceFixedSize]byte
+	counter  uint64
+	reserved uint64
+	store    NonceStore
+	closed   bool
+}
+
+// NewNonceSequence returns a NonceSequence.
+//
+// Parameters:
+//   - fixed: 4-byte fixed field, or nil to pick a random one that is unused in this process.
+//   - store: optional (can be nil). If it holds saved state, the sequence
+//     resumes from it; fixed must then be nil or equal to the saved field.
+//
+// Returns an error if the fixed field is already used by an open sequence.
+// Call Close to release it.
+func NewNonceSequence(fixed []byte, store NonceStore) (*NonceSequence, error) {
+	if fixed != nil && len(fixed) != nonceFixedSize {
+		return nil, errors.New("invalid nonce fixed field size: must be 4 bytes")
+	}
+
+	s := &NonceSequence{store: store}
+	if store != nil {
+		state, err := store.Load()
+		switch {
+		case err == nil:
+			if len(state.Fixed) != nonceFixedSize {
+				return nil, errors.New("invalid stored nonce state: fixed field must be 4 bytes")
+			}
+			if fixed != nil && string(fixed) != string(state.Fixed) {
+				return nil, errors.New("nonce fixed field does not match stored state")
+			}
+			fixed = state.Fixed
+			s.counter = state.Counter
+			s.reserved = state.Counter
+		case !errors.Is(err, ErrNonceStateNotFound):
+			return nil, err
+		}
+	}
+
+	nonceFixedFields.Lock()
+	defer nonceFixedFields.Unlock()
+
+	if fixed != nil {
+		copy(s.fixed[:], fixed)
+		if nonceFixedFields.inUse[s.fixed] {
+			return nil, errors.New("nonce fixed field already in use")
+		}
+	} else {
+		for {
+			if _, err := io.ReadFull(rand.Reader, s.fixed[:]); err != nil {
+				return nil, err
+			}
+			if !nonceFixedFields.inUse[s.fixed] {
+				break
+			}
+		}
+	}
+	nonceFixedFields.inUse[s.fixed] = true
+	return s, nil
+}
+
+// Fixed returns a copy of the sequence's fixed field.
+func (s *NonceSequence) Fixed() []byte {
+	return append([]byte(nil), s.fixed[:]...)
+}
+
+// Next returns the next nonce (fixed||counter). When a store is configured,
+// counter values are persisted before they are handed out.
+func (s *NonceSequence) Next() ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return nil, errors.New("nonce sequence is closed")
+	}
+	if s.counter == math.MaxUint64 {
+		return nil, ErrNonceExhausted
+	}
+
+	if s.store != nil && s.counter >= s.reserved {
+		reserved := s.counter + nonceReserve
+		if reserved < s.counter {
+			reserved = math.MaxUint64
+		}
+		if err := s.store.Save(NonceState{Fixed: s.Fixed(), Counter: reserved}); err != nil {
+			return nil, err
+		}
+		s.reserved = reserved
+	}
+
+	nonce := make([]byte, gcmStandardNonceSize)
+	copy(nonce, s.fixed[:])
+	binary.BigEndian.PutUint64(nonce[nonceFixedSize:], s.counter)
+	s.counter++
+	return nonce, nil
+}
+
+// Close releases the fixed field so another sequence may use it. If a store
+// is configured, the exact next counter value is saved first.
+func (s *NonceSequence) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+
+	var err error
+	if s.store != nil {
+		err = s.store.Save(NonceState{Fixed: s.Fixed(), Counter: s.counter})
+	}
+
+	nonceFixedFields.Lock()
+	delete(nonceFixedFields.inUse, s.fixed)
+	nonceFixedFields.Unlock()
+	return err
+}
+
+// GCMSealer encrypts with AES-GCM using nonces from a NonceSequence instead
+// of crypto/rand. Its output is compatible with DecryptGCM.
+type GCMSealer struct {
+	aead cipher.AEAD
+	seq  *NonceSequence
+}
+
+// NewGCMSealer returns a GCMSealer for key. The sealer owns seq: it must not
+// be used with any other key or sealer.
+//
+// Parameters:
+//   - key: 16/24/32 bytes (AES-128/192/256).
+//   - seq: nonce sequence created with NewNonceSequence.
+func NewGCMSealer(key []byte, seq *NonceSequence) (*GCMSealer, error) {
+	if seq == nil {
+		return nil, errors.New("nonce sequence must not be nil")
+	}
+
+	aead, err := NewGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	return &GCMSealer{aead: aead, seq: seq}, nil
+}
+
+// Seal encrypts plaintext with the next nonce of the sequence.
+//
+// Parameters:
+//   - plaintext: Data to be encrypted.
+//   - aad: Additional Authenticated Data (optional, can be nil).
+//
+// Returns: nonce||ciphertext, or ErrNonceExhausted once the sequence is used up.
+func (g *GCMSealer) Seal(plaintext, aad []byte) ([]byte, error) {
+	nonce, err := g.seq.Next()
+	if err != nil {
+		return nil, err
+	}
+	return g.aead.Seal(nonce, nonce, plaintext, aad), nil
+}
+
+// Close closes the underlying NonceSequence.
+func (g *GCMSealer) Close() error {
+	return g.seq.Close()
+}
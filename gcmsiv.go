@@ -0,0 +1,275 @@
+package goaes
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+)
+
+const (
+	gcmSIVNonceSize = 12
+	gcmSIVTagSize   = 16
+	// gcmSIVMaxInput is the RFC 8452 limit for plaintext and AAD (2^36 bytes).
+	gcmSIVMaxInput = 1 << 36
+)
+
+// EncryptGCMSIV encrypts plaintext using AES-GCM-SIV (RFC 8452).
+//
+// Nonce-misuse resistance: if a nonce is ever repeated under the same key,
+// an attacker only learns whether two messages (with the same AAD) were equal.
+// Unlike AES-GCM, the authentication key is NOT revealed.
+// A fresh random nonce is still generated for every call.
+//
+// Parameters:
+//   - key: 16 or 32 bytes (AEAD_AES_128_GCM_SIV or AEAD_AES_256_GCM_SIV).
+//   - plaintext: Data to be encrypted.
+//   - aad: Additional Authenticated Data (optional, can be nil).
+//
+// Returns: nonce||ciphertext
+func EncryptGCMSIV(key, plaintext, aad []byte) ([]byte, error) {
+	return SealGCMSIV(nil, key, plaintext, aad)
+}
+
+// DecryptGCMSIV decrypts data produced by EncryptGCMSIV.
+// It expects the nonce to be prepended to the ciphertext.
+//
+// Parameters:
+//   - key: same key used for encryption.
+//   - ciphertext: nonce||ciphertext.
+//   - aad: same additional data used for encryption.
+//
+// Returns: decrypted plaintext.
+func DecryptGCMSIV(key, ciphertext, aad []byte) ([]byte, error) {
+	return OpenGCMSIV(nil, key, ciphertext, aad)
+}
+
+// SealGCMSIV is the append-style form of EncryptGCMSIV: it appends
+// nonce||ciphertext to dst and returns the updated slice. dst may overlap
+// plaintext, e.g. buf[:0] to encrypt buf in place; aad must not overlap dst.
+func SealGCMSIV(dst, key, plaintext, aad []byte) ([]byte, error) {
+	aead, err := newGCMSIV(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if uint64(len(plaintext)) > gcmSIVMaxInput || uint64(len(aad)) > gcmSIVMaxInput {
+		return nil, errors.New("input too large for GCM-SIV")
+	}
+	return sealPrefixed(dst, aead, plaintext, aad)
+}
+
+// OpenGCMSIV is the append-style form of DecryptGCMSIV: it appends the
+// plaintext to dst and returns the updated slice. dst may overlap
+// ciphertext, e.g. buf[:0] to decrypt buf in place.
+func OpenGCMSIV(dst, key, ciphertext, aad []byte) ([]byte, error) {
+	aead, err := newGCMSIV(key)
+	if err != nil {
+		return nil, err
+	}
+	return openPrefixed(dst, aead, ciphertext, aad)
+}
+
+// NewGCMSIV returns AES-GCM-SIV (RFC 8452) as a cipher.AEAD, with a 12-byte
+// nonce and 16-byte tag. Repeating a nonce only reveals whether two messages
+// were equal, but nonces should still be unique.
+//
+// Parameters:
+//   - key: 16 or 32 bytes (AEAD_AES_128_GCM_SIV or AEAD_AES_256_GCM_SIV).
+func NewGCMSIV(key []byte) (cipher.AEAD, error) {
+	return newGCMSIV(key)
+}
+
+// gcmSIV implements cipher.AEAD for AES-GCM-SIV.
+type gcmSIV struct {
+	// kgk is the key-generating key; per-nonce keys are derived from it.
+	kgk    cipher.Block
+	keyLen int
+}
+
+// newGCMSIV validates the key and returns a GCM-SIV AEAD.
+func newGCMSIV(key []byte) (*gcmSIV, error) {
+	if err := validateGCMSIVKeySize(key); err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return &gcmSIV{kgk: block, keyLen: len(key)}, nil
+}
+
+func (g *gcmSIV) NonceSize() int { return gcmSIVNonceSize }
+
+func (g *gcmSIV) Overhead() int { return gcmSIVTagSize }
+
+func (g *gcmSIV) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
+	if len(nonce) != gcmSIVNonceSize {
+		panic("goaes: incorrect nonce length given to GCM-SIV")
+	}
+	if uint64(len(plaintext)) > gcmSIVMaxInput || uint64(len(additionalData)) > gcmSIVMaxInput {
+		panic("goaes: message too large for GCM-SIV")
+	}
+
+	authKey, encBlock := g.deriveKeys(nonce)
+	tag := gcmSIVTag(&authKey, encBlock, nonce, plaintext, additionalData)
+
+	ret, out := sliceForAppend(dst, len(plaintext)+gcmSIVTagSize)
+	gcmSIVCTR(encBlock, &tag, out[:len(plaintext)], plaintext)
+	copy(out[len(plaintext):], tag[:])
+	return ret
+}
+
+func (g *gcmSIV) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(nonce) != gcmSIVNonceSize {
+		panic("goaes: incorrect nonce length given to GCM-SIV")
+	}
+	if len(ciphertext) < gcmSIVTagSize || uint64(len(ciphertext)) > gcmSIVMaxInput+gcmSIVTagSize {
+		return nil, errOpen
+	}
+
+	ctLen := len(ciphertext) - gcmSIVTagSize
+	var tag [gcmSIVTagSize]byte
+	copy(tag[:], ciphertext[ctLen:])
+
+	authKey, encBlock := g.deriveKeys(nonce)
+
+	ret, out := sliceForAppend(dst, ctLen)
+	gcmSIVCTR(encBlock, &tag, out, ciphertext[:ctLen])
+
+	expected := gcmSIVTag(&authKey, encBlock, nonce, out, additionalData)
+	if subtle.ConstantTimeCompare(expected[:], tag[:]) != 1 {
+		clear(out)
+		return nil, errOpen
+	}
+	return ret, nil
+}
+
+// deriveKeys derives the per-nonce message-authentication and
+// message-encryption keys (RFC 8452, Section 4).
+func (g *gcmSIV) deriveKeys(nonce []byte) (authKey [16]byte, encBlock cipher.Block) {
+	var in, out [16]byte
+	copy(in[4:], nonce)
+
+	encKey := make([]byte, g.keyLen)
+	blocks := 2 + g.keyLen/8
+	for i := 0; i < blocks; i++ {
+		binary.LittleEndian.PutUint32(in[:4], uint32(i))
+		g.kgk.Encrypt(out[:], in[:])
+		if i < 2 {
+			copy(authKey[i*8:], out[:8])
+		} else {
+			copy(encKey[(i-2)*8:], out[:8])
+		}
+	}
+
+	// The key length was validated in newGCMSIV, so this cannot fail.
+	encBlock, _ = aes.NewCipher(encKey)
+	clear(encKey)
+	return authKey, encBlock
+}
+
+// gcmSIVTag computes the GCM-SIV tag over the AAD and plaintext.
+func gcmSIVTag(authKey *[16]byte, encBlock cipher.Block, nonce, plaintext, additionalData []byte) [16]byte {
+	p := newPolyval(authKey)
+	p.update(additionalData)
+	p.update(plaintext)
+
+	var lengths [16]byte
+	binary.LittleEndian.PutUint64(lengths[:8], uint64(len(additionalData))*8)
+	binary.LittleEndian.PutUint64(lengths[8:], uint64(len(plaintext))*8)
+	p.update(lengths[:])
+
+	s := p.sum()
+	for i := range nonce {
+		s[i] ^= nonce[i]
+	}
+	s[15] &= 0x7f
+	encBlock.Encrypt(s[:], s[:])
+	return s
+}
+
+// gcmSIVCTR applies the GCM-SIV keystream, whose initial counter block is the
+// tag with its top bit set and whose counter is the first 32 bits (little-endian).
+func gcmSIVCTR(encBlock cipher.Block, tag *[16]byte, dst, src []byte) {
+	counter := *tag
+	counter[15] |= 0x80
+
+	var ks [16]byte
+	for len(src) > 0 {
+		encBlock.Encrypt(ks[:], counter[:])
+		binary.LittleEndian.PutUint32(counter[:4], binary.LittleEndian.Uint32(counter[:4])+1)
+
+		n := subtle.XORBytes(dst, src, ks[:])
+		dst = dst[n:]
+		src = src[n:]
+	}
+}
+
+// polyvalElement is an element of GF(2^128) in POLYVAL's little-endian
+// representation: bit i of the 128-bit value is the coefficient of x^i.
+type polyvalElement struct {
+	lo, hi uint64
+}
+
+// polyval computes the POLYVAL universal hash (RFC 8452, Section 3).
+type polyval struct {
+	h, s polyvalElement
+}
+
+func newPolyval(key *[16]byte) *polyval {
+	return &polyval{h: polyvalLoad(key[:])}
+}
+
+// update absorbs data, zero-padding any trailing partial block.
+func (p *polyval) update(data []byte) {
+	var block [16]byte
+	for len(data) > 0 {
+		n := copy(block[:], data)
+		clear(block[n:])
+		data = data[n:]
+
+		x := polyvalLoad(block[:])
+		p.s.lo ^= x.lo
+		p.s.hi ^= x.hi
+		p.s = polyvalDot(p.s, p.h)
+	}
+}
+
+func (p *polyval) sum() [16]byte {
+	var out [16]byte
+	binary.LittleEndian.PutUint64(out[:8], p.s.lo)
+	binary.LittleEndian.PutUint64(out[8:], p.s.hi)
+	return out
+}
+
+func polyvalLoad(b []byte) polyvalElement {
+	return polyvalElement{
+		lo: binary.LittleEndian.Uint64(b[:8]),
+		hi: binary.LittleEndian.Uint64(b[8:16]),
+	}
+}
+
+// polyvalDot returns a*b*x^-128 modulo x^128 + x^127 + x^126 + x^121 + 1.
+// It runs in constant time with respect to both operands.
+func polyvalDot(a, b polyvalElement) polyvalElement {
+	var r polyvalElement
+	for i := 0; i < 128; i++ {
+		var bit uint64
+		if i < 64 {
+			bit = (b.lo >> i) & 1
+		} else {
+			bit = (b.hi >> (i - 64)) & 1
+		}
+		mask := -bit
+		r.lo ^= a.lo & mask
+		r.hi ^= a.hi & mask
+
+		// Divide by x: add the field polynomial if needed, then shift right.
+		lsb := r.lo & 1
+		r.lo = r.lo>>1 | r.hi<<63
+		r.hi = r.hi>>1 ^ (-lsb & 0xe100000000000000)
+	}
+	return r
+}
@@ -0,0 +1,167 @@
+package goaes_test
+
+import (
+	"bytes"
+	"testing"
+
+	goaes "github.com/fawwazid/go-aes"
+)
+
+func TestAESGCMSIV_EncryptDecrypt(t *testing.T) {
+	plaintext := []byte("The five boxing wizards jump quickly")
+	aad := []byte("header-aad")
+
+	for _, k := range []int{16, 32} {
+		key := make([]byte, k)
+		for i := 0; i < k; i++ {
+			key[i] = byte(i)
+		}
+
+		ct, err := goaes.EncryptGCMSIV(key, plaintext, aad)
+		if err != nil {
+			t.Fatalf("encrypt failed for key len %d: %v", k, err)
+		}
+
+		pt, err := goaes.DecryptGCMSIV(key, ct, aad)
+		if err != nil {
+			t.Fatalf("decrypt failed for key len %d: %v", k, err)
+		}
+
+		if !bytes.Equal(pt, plaintext) {
+			t.Fatalf("plaintext mismatch for key len %d", k)
+		}
+
+		// tamper detection: flip last byte
+		bad := make([]byte, len(ct))
+		copy(bad, ct)
+		bad[len(bad)-1] ^= 0xFF
+		_, err = goaes.DecryptGCMSIV(key, bad, aad)
+		if err == nil {
+			t.Fatalf("expected decryption error for tampered ciphertext (key len %d)", k)
+		}
+
+		_, err = goaes.DecryptGCMSIV(key, ct, []byte("other-aad"))
+		if err == nil {
+			t.Fatalf("expected decryption error for wrong aad (key len %d)", k)
+		}
+	}
+}
+
+func TestAESGCMSIV_InvalidKey(t *testing.T) {
+	for _, k := range []int{11, 24, 64} {
+		key := make([]byte, k)
+
+		_, err := goaes.EncryptGCMSIV(key, []byte("secret"), nil)
+		if err == nil {
+			t.Errorf("expected error for key len %d in EncryptGCMSIV", k)
+		}
+
+		_, err = goaes.DecryptGCMSIV(key, make([]byte, 28), nil)
+		if err == nil {
+			t.Errorf("expected error for key len %d in DecryptGCMSIV", k)
+		}
+	}
+}
+
+// TestAESGCMSIV_RFC8452 checks the RFC 8452 Appendix C vectors by decrypting
+// nonce||result, which also verifies that the computed tag matches.
+func TestAESGCMSIV_RFC8452(t *testing.T) {
+	tests := []struct {
+		name, key, nonce, plaintext, aad, result string
+	}{
+		{
+			name:   "AES-128 empty",
+			key:    "01000000000000000000000000000000",
+			nonce:  "030000000000000000000000",
+			result: "dc20e2d83f25705bb49e439eca56de25",
+		},
+		{
+			name:      "AES-128 8 bytes",
+			key:       "01000000000000000000000000000000",
+			nonce:     "030000000000000000000000",
+			plaintext: "0100000000000000",
+			result:    "b5d839330ac7b786578782fff6013b815b287c22493a364c",
+		},
+		{
+			name:      "AES-128 12 bytes",
+			key:       "01000000000000000000000000000000",
+			nonce:     "030000000000000000000000",
+			plaintext: "010000000000000000000000",
+			result:    "7323ea61d05932260047d942a4978db357391a0bc4fdec8b0d106639",
+		},
+		{
+			name:      "AES-128 16 bytes",
+			key:       "01000000000000000000000000000000",
+			nonce:     "030000000000000000000000",
+			plaintext: "01000000000000000000000000000000",
+			result:    "743f7c8077ab25f8624e2e948579cf77303aaf90f6fe21199c6068577437a0c4",
+		},
+		{
+			name:      "AES-128 with AAD",
+			key:       "01000000000000000000000000000000",
+			nonce:     "030000000000000000000000",
+			plaintext: "0200000000000000",
+			aad:       "01",
+			result:    "1e6daba35669f4273b0a1a2560969cdf790d99759abd1508",
+		},
+		{
+			name:   "AES-256 empty",
+			key:    "0100000000000000000000000000000000000000000000000000000000000000",
+			nonce:  "030000000000000000000000",
+			result: "07f5f4169bbf55a8400cd47ea6fd400f",
+		},
+		{
+			name:      "AES-256 8 bytes",
+			key:       "0100000000000000000000000000000000000000000000000000000000000000",
+			nonce:     "030000000000000000000000",
+			plaintext: "0100000000000000",
+			result:    "c2ef328e5c71c83b843122130f7364b761e0b97427e3df28",
+		},
+		{
+			name:      "AES-256 12 bytes",
+			key:       "0100000000000000000000000000000000000000000000000000000000000000",
+			nonce:     "030000000000000000000000",
+			plaintext: "010000000000000000000000",
+			result:    "9aab2aeb3faa0a34aea8e2b18ca50da9ae6559e48fd10f6e5c9ca17e",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key := mustHex(t, tt.key)
+			in := append(mustHex(t, tt.nonce), mustHex(t, tt.result)...)
+
+			pt, err := goaes.DecryptGCMSIV(key, in, mustHex(t, tt.aad))
+			if err != nil {
+				t.Fatalf("decrypt failed: %v", err)
+			}
+			if !bytes.Equal(pt, mustHex(t, tt.plaintext)) {
+				t.Fatalf("plaintext = %x, want %s", pt, tt.plaintext)
+			}
+		})
+	}
+}
+
+func TestAESGCMSIV_NewGCMSIV(t *testing.T) {
+	key := bytes.Repeat([]byte{0x08}, 16)
+	plaintext := []byte("misuse-resistant AEAD object")
+	aad := []byte("aad")
+
+	aead, err := goaes.NewGCMSIV(key)
+	if err != nil {
+		t.Fatalf("NewGCMSIV failed: %v", err)
+	}
+
+	ct, err := goaes.EncryptGCMSIV(key, plaintext, aad)
+	if err != nil {
+		t.Fatalf("encrypt failed: %v", err)
+	}
+	pt, err := aead.Open(nil, ct[:aead.NonceSize()], ct[aead.NonceSize():], aad)
+	if err != nil || !bytes.Equal(pt, plaintext) {
+		t.Fatalf("Open of EncryptGCMSIV output = %q, %v", pt, err)
+	}
+
+	if _, err := goaes.NewGCMSIV(make([]byte, 24)); err == nil {
+		t.Fatal("expected error for 24-byte key")
+	}
+}
@@ -0,0 +1,274 @@
+package goaes
+
+import (
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+)
+
+const (
+	// gcmStreamSaltSize is the length of the random per-stream header.
+	gcmStreamSaltSize = 32
+	// gcmStreamSegmentSize is the plaintext length of every segment but the
+	// last, which may be shorter (or empty).
+	gcmStreamSegmentSize = 64 << 10
+	// gcmStreamTagSize is the GCM tag length appended to each segment.
+	gcmStreamTagSize = 16
+)
+
+// errStreamClosed is returned by writes to a closed stream writer.
+var errStreamClosed = errors.New("write to closed stream")
+
+// NewGCMEncryptWriter returns a writer that encrypts everything written to it
+// into w, using the STREAM construction over AES-GCM, so data of any size can
+// be encrypted with constant memory.
+//
+// The output is a random 32-byte salt followed by segments of 64 KiB of
+// plaintext, each sealed with its own tag. A per-stream key is derived from
+// key and the salt with HKDF-SHA256, and each segment's nonce encodes its
+// index and whether it is the last one. Segments therefore cannot be
+// reordered, dropped or appended to without detection by
+// NewGCMDecryptReader.
+//
+// Close must be called to write the final segment; it does not close w.
+//
+// Parameters:
+//   - w: destination for salt||segments.
+//   - key: 16/24/32 bytes (AES-128/192/256).
+//   - aad: Additional Authenticated Data bound to every segment (optional, can be nil).
+//
+// Returns an error if the salt cannot be generated or written.
+func NewGCMEncryptWriter(w io.Writer, key, aad []byte) (io.WriteCloser, error) {
+	if err := validateKeySize(key); err != nil {
+		return nil, err
+	}
+
+	salt := make([]byte, gcmStreamSaltSize)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, err
+	}
+
+	aead, err := gcmStreamAEAD(key, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := w.Write(salt); err != nil {
+		return nil, err
+	}
+
+	return &gcmStreamWriter{
+		w:    w,
+		aead: aead,
+		aad:  append([]byte(nil), aad...),
+		buf:  make([]byte, 0, gcmStreamSegmentSize+gcmStreamTagSize),
+	}, nil
+}
+
+// NewGCMDecryptReader returns a reader that decrypts a stream produced by
+// NewGCMEncryptWriter from r.
+//
+// Each segment is authenticated before any of its plaintext is returned, so
+// Read never releases unauthenticated data. A truncated, reordered or
+// extended stream, or a wrong key or aad, makes Read fail with an
+// authentication error once the offending segment is reached; data from
+// earlier segments has already been verified. io.EOF is only returned after
+// the final segment has been verified.
+//
+// Parameters:
+//   - r: source of salt||segments.
+//   - key: same key used for encryption.
+//   - aad: same additional data used for encryption.
+//
+// Returns an error if the salt cannot be read.
+func NewGCMDecryptReader(r io.Reader, key, aad []byte) (io.Reader, error) {
+	if err := validateKeySize(key); err != nil {
+		return nil, err
+	}
+
+	salt := make([]byte, gcmStreamSaltSize)
+	if _, err := io.ReadFull(r, salt); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, errors.New("ciphertext too short")
+		}
+		return nil, err
+	}
+
+	aead, err := gcmStreamAEAD(key, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &gcmStreamReader{
+		r:    r,
+		aead: aead,
+		aad:  append([]byte(nil), aad...),
+		// One spare byte tells a full final segment from a full middle one.
+		buf: make([]byte, gcmStreamSegmentSize+gcmStreamTagSize+1),
+	}, nil
+}
+
+// gcmStreamAEAD derives the per-stream key from key and salt and returns
+// AES-GCM keyed with it.
+func gcmStreamAEAD(key, salt []byte) (cipher.AEAD, error) {
+	streamKey, err := hkdf.Key(sha256.New, key, salt, "goaes gcm-stream", len(key))
+	if err != nil {
+		return nil, err
+	}
+	defer clear(streamKey)
+	return NewGCM(streamKey)
+}
+
+// gcmStreamNonce returns the nonce of segment i: a 64-bit big-endian segment
+// index followed by 0x01 for the last segment or 0x00 otherwise. Nonces only
+// need to be unique per stream, since every stream has its own key.
+func gcmStreamNonce(i uint64, last bool) [gcmStandardNonceSize]byte {
+	var nonce [gcmStandardNonceSize]byte
+	binary.BigEndian.PutUint64(nonce[3:], i)
+	if last {
+		nonce[gcmStandardNonceSize-1] = 1
+	}
+	return nonce
+}
+
+// gcmStreamWriter implements io.WriteCloser for NewGCMEncryptWriter.
+type gcmStreamWriter struct {
+	w       io.Writer
+	aead    cipher.AEAD
+	aad     []byte
+	counter uint64
+	buf     []byte // pending plaintext of the current segment
+	err     error  // sticky; set by a failed write or by Close
+}
+
+func (s *gcmStreamWriter) Write(p []byte) (int, error) {
+	if s.err != nil {
+		return 0, s.err
+	}
+
+	n := 0
+	for len(p) > 0 {
+		// A full segment is only sealed once more data arrives, because
+		// until then it may turn out to be the last one.
+		if len(s.buf) == gcmStreamSegmentSize {
+			if err := s.seal(false); err != nil {
+				s.err = err
+				return n, err
+			}
+		}
+		k := copy(s.buf[len(s.buf):gcmStreamSegmentSize], p)
+		s.buf = s.buf[:len(s.buf)+k]
+		p = p[k:]
+		n += k
+	}
+	return n, nil
+}
+
+// Close seals and writes the final segment. It does not close the
+// underlying writer.
+func (s *gcmStreamWriter) Close() error {
+	if s.err != nil {
+		if s.err == errStreamClosed {
+			return nil
+		}
+		return s.err
+	}
+
+	err := s.seal(true)
+	clear(s.buf[:cap(s.buf)])
+	s.err = errStreamClosed
+	return err
+}
+
+// seal encrypts the buffered segment in place and writes it out.
+func (s *gcmStreamWriter) seal(last bool) error {
+	if s.counter == math.MaxUint64 {
+		return errors.New("stream too long")
+	}
+
+	nonce := gcmStreamNonce(s.counter, last)
+	ct := s.aead.Seal(s.buf[:0], nonce[:], s.buf, s.aad)
+	if _, err := s.w.Write(ct); err != nil {
+		return err
+	}
+	s.counter++
+	s.buf = s.buf[:0]
+	return nil
+}
+
+// gcmStreamReader implements io.Reader for NewGCMDecryptReader.
+type gcmStreamReader struct {
+	r       io.Reader
+	aead    cipher.AEAD
+	aad     []byte
+	counter uint64
+	buf     []byte // ciphertext of one segment plus one read-ahead byte
+	ahead   bool   // buf[len(buf)-1] holds the first byte of the next segment
+	pt      []byte // authenticated plaintext not yet returned
+	err     error  // sticky; io.EOF after the final segment
+}
+
+func (s *gcmStreamReader) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	for len(s.pt) == 0 {
+		if s.err != nil {
+			return 0, s.err
+		}
+		s.err = s.next()
+	}
+
+	n := copy(p, s.pt)
+	s.pt = s.pt[n:]
+	return n, nil
+}
+
+// next reads, authenticates and decrypts the next segment into s.pt. It
+// returns io.EOF once the final segment has been opened.
+func (s *gcmStreamReader) next() error {
+	const encSegment = gcmStreamSegmentSize + gcmStreamTagSize
+
+	n := 0
+	if s.ahead {
+		s.buf[0] = s.buf[encSegment]
+		n = 1
+	}
+
+	m, err := io.ReadFull(s.r, s.buf[n:])
+	n += m
+	last := false
+	switch err {
+	case nil:
+		s.ahead = true
+	case io.EOF, io.ErrUnexpectedEOF:
+		// Fewer bytes than a segment plus one: this is the last segment.
+		last = true
+		s.ahead = false
+	default:
+		return err
+	}
+
+	segment := s.buf[:min(n, encSegment)]
+	if len(segment) < gcmStreamTagSize || s.counter == math.MaxUint64 {
+		return errOpen
+	}
+
+	nonce := gcmStreamNonce(s.counter, last)
+	pt, err := s.aead.Open(segment[:0], nonce[:], segment, s.aad)
+	if err != nil {
+		return errOpen
+	}
+	s.counter++
+	s.pt = pt
+
+	if last {
+		return io.EOF
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+package goaes_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"testing/iotest"
+
+	goaes "github.com/fawwazid/go-aes"
+)
+
+const (
+	streamSaltSize    = 32
+	streamSegmentSize = 64 << 10
+	streamEncSegment  = streamSegmentSize + 16
+)
+
+// encryptStream encrypts plaintext through NewGCMEncryptWriter, writing it in
+// chunks of the given size.
+func encryptStream(t *testing.T, key, aad, plaintext []byte, chunk int) []byte {
+	t.Helper()
+
+	var out bytes.Buffer
+	w, err := goaes.NewGCMEncryptWriter(&out, key, aad)
+	if err != nil {
+		t.Fatalf("NewGCMEncryptWriter failed: %v", err)
+	}
+	for p := plaintext; len(p) > 0; {
+		n := min(chunk, len(p))
+		if _, err := w.Write(p[:n]); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+		p = p[n:]
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	return out.Bytes()
+}
+
+// decryptStream reads everything from NewGCMDecryptReader over ciphertext.
+func decryptStream(key, aad, ciphertext []byte) ([]byte, error) {
+	r, err := goaes.NewGCMDecryptReader(bytes.NewReader(ciphertext), key, aad)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
+
+func TestGCMStream_EncryptDecrypt(t *testing.T) {
+	key := bytes.Repeat([]byte{0x0a}, 32)
+	aad := []byte("backup-2024")
+
+	sizes := []int{0, 1, streamSegmentSize - 1, streamSegmentSize, streamSegmentSize + 1, 3*streamSegmentSize + 5}
+	for _, size := range sizes {
+		plaintext := make([]byte, size)
+		for i := range plaintext {
+			plaintext[i] = byte(i * 7)
+		}
+
+		for _, chunk := range []int{1000, streamSegmentSize, 1 << 20} {
+			ct := encryptStream(t, key, aad, plaintext, chunk)
+
+			// Even empty input produces one (final) segment.
+			segments := max(1, (size+streamSegmentSize-1)/streamSegmentSize)
+			if want := streamSaltSize + size + 16*segments; len(ct) != want {
+				t.Fatalf("size %d: ciphertext length = %d, want %d", size, len(ct), want)
+			}
+
+			pt, err := decryptStream(key, aad, ct)
+			if err != nil {
+				t.Fatalf("size %d, chunk %d: decrypt failed: %v", size, chunk, err)
+			}
+			if !bytes.Equal(pt, plaintext) {
+				t.Fatalf("size %d, chunk %d: plaintext mismatch", size, chunk)
+			}
+		}
+	}
+}
+
+func TestGCMStream_SmallReads(t *testing.T) {
+	key := bytes.Repeat([]byte{0x0b}, 16)
+	plaintext := bytes.Repeat([]byte("0123456789"), streamSegmentSize/5)
+	ct := encryptStream(t, key, nil, plaintext, 4096)
+
+	r, err := goaes.NewGCMDecryptReader(iotest.HalfReader(bytes.NewReader(ct)), key, nil)
+	if err != nil {
+		t.Fatalf("NewGCMDecryptReader failed: %v", err)
+	}
+	if err := iotest.TestReader(r, plaintext); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGCMStream_Tampering(t *testing.T) {
+	key := bytes.Repeat([]byte{0x0c}, 32)
+	aad := []byte("aad")
+	plaintext := bytes.Repeat([]byte{'s'}, 3*streamSegmentSize+100)
+	ct := encryptStream(t, key, aad, plaintext, 1<<20)
+
+	segment := func(i int) []byte {
+		start := streamSaltSize + i*streamEncSegment
+		return ct[start:min(start+streamEncSegment, len(ct))]
+	}
+	join := func(parts ...[]byte) []byte {
+		var b []byte
+		for _, p := range parts {
+			b = append(b, p...)
+		}
+		return b
+	}
+	salt := ct[:streamSaltSize]
+
+	tests := []struct {
+		name string
+		ct   []byte
+		// good is how many leading segments authenticate before the failure.
+		good int
+	}{
+		{"truncated at segment boundary", ct[:streamSaltSize+3*streamEncSegment], 2},
+		{"final segment dropped mid-way", ct[:len(ct)-10], 3},
+		{"only header", salt, 0},
+		{"reordered", join(salt, segment(1), segment(0), segment(2), segment(3)), 0},
+		{"middle segment repeated", join(salt, segment(0), segment(0), segment(2), segment(3)), 1},
+		{"appended data", join(ct, []byte{0}), 3},
+		{"appended segment", join(ct, segment(3)), 3},
+		{"flipped bit in segment 2", func() []byte {
+			b := append([]byte(nil), ct...)
+			b[streamSaltSize+2*streamEncSegment+5] ^= 1
+			return b
+		}(), 2},
+		{"flipped bit in salt", func() []byte {
+			b := append([]byte(nil), ct...)
+			b[0] ^= 1
+			return b
+		}(), 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pt, err := decryptStream(key, aad, tt.ct)
+			if err == nil {
+				t.Fatal("expected an authentication error")
+			}
+			// Only whole, verified segments may have been released.
+			if len(pt) != tt.good*streamSegmentSize {
+				t.Fatalf("released %d bytes, want %d", len(pt), tt.good*streamSegmentSize)
+			}
+			if !bytes.Equal(pt, plaintext[:len(pt)]) {
+				t.Fatal("released plaintext does not match")
+			}
+		})
+	}
+}
+
+func TestGCMStream_WrongKeyOrAAD(t *testing.T) {
+	key := bytes.Repeat([]byte{0x0d}, 32)
+	ct := encryptStream(t, key, []byte("right"), []byte("short message"), 100)
+
+	if _, err := decryptStream(key, []byte("wrong"), ct); err == nil {
+		t.Fatal("expected error for wrong aad")
+	}
+	if _, err := decryptStream(bytes.Repeat([]byte{0x0e}, 32), []byte("right"), ct); err == nil {
+		t.Fatal("expected error for wrong key")
+	}
+	if _, err := goaes.NewGCMDecryptReader(bytes.NewReader(ct[:10]), key, nil); err == nil {
+		t.Fatal("expected error for truncated header")
+	}
+}
+
+func TestGCMStream_Writer(t *testing.T) {
+	key := bytes.Repeat([]byte{0x0f}, 24)
+
+	if _, err := goaes.NewGCMEncryptWriter(io.Discard, make([]byte, 10), nil); err == nil {
+		t.Fatal("expected error for invalid key size")
+	}
+
+	w, err := goaes.NewGCMEncryptWriter(io.Discard, key, nil)
+	if err != nil {
+		t.Fatalf("NewGCMEncryptWriter failed: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("second Close failed: %v", err)
+	}
+	if _, err := w.Write([]byte("late")); err == nil {
+		t.Fatal("expected error writing after Close")
+	}
+
+	// Errors from the destination are reported and sticky.
+	fail := errors.New("disk full")
+	w, err = goaes.NewGCMEncryptWriter(&failingWriter{after: 1, err: fail}, key, nil)
+	if err != nil {
+		t.Fatalf("NewGCMEncryptWriter failed: %v", err)
+	}
+	if _, err := w.Write(make([]byte, streamSegmentSize+1)); !errors.Is(err, fail) {
+		t.Fatalf("Write error = %v, want %v", err, fail)
+	}
+	if err := w.Close(); !errors.Is(err, fail) {
+		t.Fatalf("Close error = %v, want %v", err, fail)
+	}
+}
+
+// failingWriter accepts the given number of writes, then fails.
+type failingWriter struct {
+	after int
+	err   error
+}
+
+func (f *failingWriter) Write(p []byte) (int, error) {
+	if f.after == 0 {
+		return 0, f.err
+	}
+	f.after--
+	return len(p), nil
+}
@@ -0,0 +1,253 @@
+package goaes
+
+import (
+	"crypto/cipher"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"hash"
+	"math/bits"
+)
+
+const (
+	gmacNonceSize = 12
+	gmacSize      = 16
+)
+
+// GMAC returns the 16-byte AES-GMAC tag of data.
+//
+// NIST SP 800-38D: GMAC is GCM with no plaintext, so it provides authenticity
+// only. It shares GCM's nonce requirement: a (key, nonce) pair MUST NEVER be
+// used for two different messages. Reuse reveals the hash subkey and lets an
+// attacker forge tags for any data, including GCM ciphertexts under the same key.
+// Generate a fresh nonce with GenerateNonce(0) for every message, and transmit
+// it alongside the tag.
+//
+// Parameters:
+//   - key: 16/24/32 bytes (AES-128/192/256).
+//   - nonce: 12 bytes, unique per message.
+//   - data: Data to be authenticated (not encrypted).
+//
+// Returns: tag.
+func GMAC(key, nonce, data []byte) ([]byte, error) {
+	block, err := newCipherBlock(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(nonce) != gmacNonceSize {
+		return nil, errors.New("invalid GMAC nonce size: must be 12 bytes")
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nil, nonce, nil, data), nil
+}
+
+// VerifyGMAC checks in constant time that tag is the AES-GMAC of data under
+// the given key and nonce.
+//
+// Parameters:
+//   - key: same key used to compute the tag.
+//   - nonce: same nonce used to compute the tag.
+//   - data: Data that was authenticated.
+//   - tag: 16-byte tag to check.
+//
+// Returns: nil if the tag is valid.
+func VerifyGMAC(key, nonce, data, tag []byte) error {
+	expected, err := GMAC(key, nonce, data)
+	if err != nil {
+		return err
+	}
+	if subtle.ConstantTimeCompare(expected, tag) != 1 {
+		return errOpen
+	}
+	return nil
+}
+
+// NewGMAC returns a hash.Hash computing AES-GMAC over streamed data, bound
+// to a single nonce.
+//
+// Because a nonce must authenticate exactly one message, the returned hash
+// cannot be reused: calling Write or Reset after Sum panics. Create a new
+// hash with a fresh nonce for each message instead.
+//
+// Performance: GHASH is computed in portable constant-time Go, which is
+// typically 30 to 50 times slower than the hardware-accelerated one-shot
+// GMAC on CPUs with AES and carry-less multiply instructions. Prefer GMAC
+// when the whole message is available in memory.
+//
+// Parameters:
+//   - key: 16/24/32 bytes (AES-128/192/256).
+//   - nonce: 12 bytes, unique per message.
+//
+// Returns: hash.Hash whose Sum appends the 16-byte tag.
+func NewGMAC(key, nonce []byte) (hash.Hash, error) {
+	block, err := newCipherBlock(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(nonce) != gmacNonceSize {
+		return nil, errors.New("invalid GMAC nonce size: must be 12 bytes")
+	}
+
+	g := &gmac{}
+	var h [16]byte
+	block.Encrypt(h[:], h[:])
+	g.h = ghashLoad(h[:])
+
+	// J0 = nonce || 0^31 || 1 for 96-bit nonces.
+	var j0 [16]byte
+	copy(j0[:], nonce)
+	j0[15] = 1
+	block.Encrypt(g.mask[:], j0[:])
+	return g, nil
+}
+
+// gmac implements hash.Hash for AES-GMAC with a fixed nonce.
+type gmac struct {
+	h      ghashElement
+	mask   [16]byte // E(K, J0)
+	y      ghashElement
+	buf    [16]byte
+	n      int
+	length uint64
+	summed bool
+}
+
+// Write panics once Sum has been called, since a tag over further data would
+// authenticate a second message under the same nonce.
+func (g *gmac) Write(p []byte) (int, error) {
+	if g.summed {
+		panic("goaes: GMAC hash written after Sum; create a new one with a fresh nonce")
+	}
+	written := len(p)
+	g.length += uint64(len(p))
+	for len(p) > 0 {
+		m := copy(g.buf[g.n:], p)
+		g.n += m
+		p = p[m:]
+		if g.n == len(g.buf) {
+			g.y = ghashUpdate(g.y, g.h, g.buf[:])
+			g.n = 0
+		}
+	}
+	return written, nil
+}
+
+func (g *gmac) Sum(b []byte) []byte {
+	g.summed = true
+
+	y := g.y
+	if g.n > 0 {
+		var last [16]byte
+		copy(last[:], g.buf[:g.n])
+		y = ghashUpdate(y, g.h, last[:])
+	}
+
+	var lengths [16]byte
+	binary.BigEndian.PutUint64(lengths[:8], g.length*8)
+	y = ghashUpdate(y, g.h, lengths[:])
+
+	var tag [16]byte
+	binary.BigEndian.PutUint64(tag[:8], y.hi)
+	binary.BigEndian.PutUint64(tag[8:], y.lo)
+	subtle.XORBytes(tag[:], tag[:], g.mask[:])
+	return append(b, tag[:]...)
+}
+
+// Reset discards any data written so far. It panics once Sum has been
+// called, since authenticating another message would reuse the nonce.
+func (g *gmac) Reset() {
+	if g.summed {
+		panic("goaes: GMAC hash reused after Sum; create a new one with a fresh nonce")
+	}
+	g.y = ghashElement{}
+	g.n = 0
+	g.length = 0
+}
+
+func (g *gmac) Size() int { return gmacSize }
+
+func (g *gmac) BlockSize() int { return len(g.buf) }
+
+// ghashElement is an element of GF(2^128) in GCM's bit-reflected, big-endian
+// representation: hi holds bytes 0-7 and lo bytes 8-15.
+type ghashElement struct {
+	hi, lo uint64
+}
+
+func ghashLoad(b []byte) ghashElement {
+	return ghashElement{
+		hi: binary.BigEndian.Uint64(b[:8]),
+		lo: binary.BigEndian.Uint64(b[8:16]),
+	}
+}
+
+// ghashUpdate absorbs one 16-byte block: y = (y xor block) * h.
+func ghashUpdate(y, h ghashElement, block []byte) ghashElement {
+	x := ghashLoad(block)
+	y.hi ^= x.hi
+	y.lo ^= x.lo
+	return ghashMul(y, h)
+}
+
+// ghashMul multiplies x and y in GF(2^128) modulo x^128 + x^7 + x^2 + x + 1,
+// in GCM's bit-reflected convention. It uses the constant-time Karatsuba
+// method of BearSSL's ghash_ctmul64: three 64x64 carry-less products for the
+// low halves and three on bit-reversed inputs for the high halves, followed
+// by a shift and reduction.
+func ghashMul(x, y ghashElement) ghashElement {
+	x0, x1 := x.lo, x.hi
+	h0, h1 := y.lo, y.hi
+	x0r, x1r := bits.Reverse64(x0), bits.Reverse64(x1)
+	h0r, h1r := bits.Reverse64(h0), bits.Reverse64(h1)
+
+	z0 := clmulLow(x0, h0)
+	z1 := clmulLow(x1, h1)
+	z2 := clmulLow(x0^x1, h0^h1)
+	z0h := clmulLow(x0r, h0r)
+	z1h := clmulLow(x1r, h1r)
+	z2h := clmulLow(x0r^x1r, h0r^h1r)
+	z2 ^= z0 ^ z1
+	z2h ^= z0h ^ z1h
+	z0h = bits.Reverse64(z0h) >> 1
+	z1h = bits.Reverse64(z1h) >> 1
+	z2h = bits.Reverse64(z2h) >> 1
+
+	// The 256-bit product v3:v2:v1:v0, shifted left by one for the reflected
+	// convention, then reduced.
+	v0, v1, v2, v3 := z0, z0h^z2, z1^z2h, z1h
+	v3 = v3<<1 | v2>>63
+	v2 = v2<<1 | v1>>63
+	v1 = v1<<1 | v0>>63
+	v0 <<= 1
+
+	v2 ^= v0 ^ v0>>1 ^ v0>>2 ^ v0>>7
+	v1 ^= v0<<63 ^ v0<<62 ^ v0<<57
+	v3 ^= v1 ^ v1>>1 ^ v1>>2 ^ v1>>7
+	v2 ^= v1<<63 ^ v1<<62 ^ v1<<57
+	return ghashElement{hi: v3, lo: v2}
+}
+
+// clmulLow returns the low 64 bits of the carry-less product of x and y,
+// using integer multiplications on bits spaced four apart so that carries
+// never reach a bit that is kept.
+func clmulLow(x, y uint64) uint64 {
+	const (
+		m0 = 0x1111111111111111
+		m1 = 0x2222222222222222
+		m2 = 0x4444444444444444
+		m3 = 0x8888888888888888
+	)
+	x0, x1, x2, x3 := x&m0, x&m1, x&m2, x&m3
+	y0, y1, y2, y3 := y&m0, y&m1, y&m2, y&m3
+	z0 := x0*y0 ^ x1*y3 ^ x2*y2 ^ x3*y1
+	z1 := x0*y1 ^ x1*y0 ^ x2*y3 ^ x3*y2
+	z2 := x0*y2 ^ x1*y1 ^ x2*y0 ^ x3*y3
+	z3 := x0*y3 ^ x1*y2 ^ x2*y1 ^ x3*y0
+	return z0&m0 | z1&m1 | z2&m2 | z3&m3
+}
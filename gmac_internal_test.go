@@ -0,0 +1,49 @@
+package goaes
+
+import (
+	"crypto/rand"
+	"testing"
+)
+
+// ghashMulReference is the bit-serial multiplication of NIST SP 800-38D,
+// Algorithm 1.
+func ghashMulReference(x, y ghashElement) ghashElement {
+	var z ghashElement
+	v := y
+	for i := 0; i < 128; i++ {
+		var bit uint64
+		if i < 64 {
+			bit = (x.hi >> (63 - i)) & 1
+		} else {
+			bit = (x.lo >> (127 - i)) & 1
+		}
+		mask := -bit
+		z.hi ^= v.hi & mask
+		z.lo ^= v.lo & mask
+
+		lsb := v.lo & 1
+		v.lo = v.lo>>1 | v.hi<<63
+		v.hi = v.hi>>1 ^ (-lsb & 0xe100000000000000)
+	}
+	return z
+}
+
+func TestGHASHMul_MatchesReference(t *testing.T) {
+	var b [32]byte
+	for i := 0; i < 1000; i++ {
+		rand.Read(b[:])
+		x, y := ghashLoad(b[:16]), ghashLoad(b[16:])
+		if got, want := ghashMul(x, y), ghashMulReference(x, y); got != want {
+			t.Fatalf("ghashMul(%x, %x) = %x, want %x", b[:16], b[16:], got, want)
+		}
+	}
+
+	one := ghashElement{hi: 1 << 63} // the multiplicative identity
+	all := ghashElement{hi: ^uint64(0), lo: ^uint64(0)}
+	if got := ghashMul(all, one); got != all {
+		t.Fatalf("x * 1 = %x, want %x", got, all)
+	}
+	if got, want := ghashMul(all, all), ghashMulReference(all, all); got != want {
+		t.Fatalf("all-ones square = %x, want %x", got, want)
+	}
+}
@@ -0,0 +1,147 @@
+package goaes_test
+
+import (
+	"bytes"
+	"testing"
+
+	goaes "github.com/fawwazid/go-aes"
+)
+
+func TestGMAC_Vector(t *testing.T) {
+	// GCM specification Test Case 1: zero key and IV, no data.
+	tag, err := goaes.GMAC(make([]byte, 16), make([]byte, 12), nil)
+	if err != nil {
+		t.Fatalf("GMAC failed: %v", err)
+	}
+	if want := mustHex(t, "58e2fccefa7e3061367f1d57a4e7455a"); !bytes.Equal(tag, want) {
+		t.Fatalf("GMAC = %x, want %x", tag, want)
+	}
+}
+
+func TestGMAC_StreamingMatchesOneShot(t *testing.T) {
+	data := bytes.Repeat([]byte("large unencrypted header "), 20)
+
+	for _, k := range []int{16, 24, 32} {
+		key := make([]byte, k)
+		for i := 0; i < k; i++ {
+			key[i] = byte(i)
+		}
+
+		for _, n := range []int{0, 1, 15, 16, 17, 64, len(data)} {
+			nonce, err := goaes.GenerateNonce(0)
+			if err != nil {
+				t.Fatalf("nonce generation failed: %v", err)
+			}
+
+			want, err := goaes.GMAC(key, nonce, data[:n])
+			if err != nil {
+				t.Fatalf("GMAC failed for key len %d: %v", k, err)
+			}
+
+			h, err := goaes.NewGMAC(key, nonce)
+			if err != nil {
+				t.Fatalf("NewGMAC failed for key len %d: %v", k, err)
+			}
+			for off := 0; off < n; off += 5 {
+				h.Write(data[off:min(off+5, n)])
+			}
+			if got := h.Sum(nil); !bytes.Equal(got, want) {
+				t.Fatalf("streaming GMAC (key len %d, data len %d) = %x, want %x", k, n, got, want)
+			}
+
+			if err := goaes.VerifyGMAC(key, nonce, data[:n], want); err != nil {
+				t.Fatalf("VerifyGMAC failed for key len %d, data len %d: %v", k, n, err)
+			}
+
+			bad := make([]byte, len(want))
+			copy(bad, want)
+			bad[0] ^= 0xFF
+			if err := goaes.VerifyGMAC(key, nonce, data[:n], bad); err == nil {
+				t.Fatalf("expected error for tampered tag (key len %d, data len %d)", k, n)
+			}
+		}
+	}
+}
+
+func TestGMAC_InvalidParams(t *testing.T) {
+	key := make([]byte, 16)
+
+	if _, err := goaes.GMAC([]byte("invalid-key"), make([]byte, 12), nil); err == nil {
+		t.Error("expected error for invalid key size in GMAC")
+	}
+	for _, n := range []int{0, 8, 16} {
+		if _, err := goaes.GMAC(key, make([]byte, n), nil); err == nil {
+			t.Errorf("expected error for nonce size %d in GMAC", n)
+		}
+		if _, err := goaes.NewGMAC(key, make([]byte, n)); err == nil {
+			t.Errorf("expected error for nonce size %d in NewGMAC", n)
+		}
+	}
+}
+
+func TestGMAC_ResetAfterSumPanics(t *testing.T) {
+	h, err := goaes.NewGMAC(make([]byte, 16), make([]byte, 12))
+	if err != nil {
+		t.Fatalf("NewGMAC failed: %v", err)
+	}
+
+	// Reset before Sum is allowed.
+	h.Write([]byte("discarded"))
+	h.Reset()
+	h.Write([]byte("message"))
+	h.Sum(nil)
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic when resetting a GMAC hash after Sum")
+		}
+	}()
+	h.Reset()
+}
+
+func TestGMAC_WriteAfterSumPanics(t *testing.T) {
+	h, err := goaes.NewGMAC(make([]byte, 16), make([]byte, 12))
+	if err != nil {
+		t.Fatalf("NewGMAC failed: %v", err)
+	}
+
+	h.Write([]byte("message A"))
+	tag := h.Sum(nil)
+	// Sum again without new data returns the same tag.
+	if again := h.Sum(nil); !bytes.Equal(again, tag) {
+		t.Fatalf("second Sum = %x, want %x", again, tag)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic when writing to a GMAC hash after Sum")
+		}
+	}()
+	h.Write([]byte("message B"))
+}
+
+func BenchmarkGMAC(b *testing.B) {
+	key := make([]byte, 32)
+	nonce := make([]byte, 12)
+	data := make([]byte, 1<<20)
+
+	b.Run("oneshot", func(b *testing.B) {
+		b.SetBytes(int64(len(data)))
+		for i := 0; i < b.N; i++ {
+			if _, err := goaes.GMAC(key, nonce, data); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("streaming", func(b *testing.B) {
+		b.SetBytes(int64(len(data)))
+		for i := 0; i < b.N; i++ {
+			h, err := goaes.NewGMAC(key, nonce)
+			if err != nil {
+				b.Fatal(err)
+			}
+			h.Write(data)
+			h.Sum(nil)
+		}
+	})
+}
@@ -0,0 +1,151 @@
+package goaes
+
+import (
+	"container/list"
+	"crypto/cipher"
+	"crypto/subtle"
+	"errors"
+	"hash/maphash"
+	"sync"
+	"sync/atomic"
+)
+
+// activeKeyCache is the cache used by EncryptGCM and DecryptGCM, or nil when
+// caching is disabled (the default).
+var activeKeyCache atomic.Pointer[keyCache]
+
+// SetKeyCacheSize enables an in-process cache of prepared AES-GCM objects
+// for EncryptGCM and DecryptGCM, holding at most size keys. A size of 0
+// disables the cache. The cache is off by default.
+//
+// Key expansion and GHASH table setup then happen once per key instead of on
+// every call, which dominates the cost of small messages. The cache is safe
+// for concurrent use and evicts the least recently used key when full.
+//
+// Security: the cache keeps a copy of every cached key in memory until it is
+// evicted, at which point the copy is zeroized. The expanded round keys
+// inside crypto/aes are not reachable from this package; they are released
+// to the garbage collector on eviction. Replacing or disabling the cache
+// evicts every entry.
+func SetKeyCacheSize(size int) error {
+	if size < 0 {
+		return errors.New("key cache size must not be negative")
+	}
+
+	var next *keyCache
+	if size > 0 {
+		next = newKeyCache(size)
+	}
+	if old := activeKeyCache.Swap(next); old != nil {
+		old.purge()
+	}
+	return nil
+}
+
+// keyCacheEntry is one cached key and its prepared cipher objects.
+type keyCacheEntry struct {
+	id   uint64
+	key  []byte
+	gcm  cipher.AEAD
+	elem *list.Element
+}
+
+// keyCache is a bounded LRU cache of prepared AES-GCM objects. Entries are
+// indexed by a seeded hash of the key, and a hit also requires the full key
+// to match, so the key material itself is never used as a map key.
+type keyCache struct {
+	mu      sync.Mutex
+	seed    maphash.Seed
+	size    int
+	entries map[uint64]*keyCacheEntry
+	lru     *list.List // front is most recently used
+	purged  bool       // set by purge; no entries are added afterwards
+}
+
+func newKeyCache(size int) *keyCache {
+	return &keyCache{
+		seed:    maphash.MakeSeed(),
+		size:    size,
+		entries: make(map[uint64]*keyCacheEntry),
+		lru:     list.New(),
+	}
+}
+
+// gcm returns the cached AES-GCM for key, preparing and caching it on a miss.
+func (c *keyCache) gcm(key []byte) (cipher.AEAD, error) {
+	id := maphash.Bytes(c.seed, key)
+
+	c.mu.Lock()
+	if e, ok := c.entries[id]; ok && subtle.ConstantTimeCompare(e.key, key) == 1 {
+		c.lru.MoveToFront(e.elem)
+		gcm := e.gcm
+		c.mu.Unlock()
+		return gcm, nil
+	}
+	c.mu.Unlock()
+
+	gcm, err := NewGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// The cache may have been replaced and purged while the key was being
+	// prepared. An entry added now would never be evicted, leaving its key
+	// copy in memory, so the result is returned uncached.
+	if c.purged {
+		return gcm, nil
+	}
+
+	// Another goroutine may have prepared the same key meanwhile, or a
+	// different key may share the hash; either way the old entry goes.
+	if e, ok := c.entries[id]; ok {
+		c.evict(e)
+	}
+	for c.lru.Len() >= c.size {
+		c.evict(c.lru.Back().Value.(*keyCacheEntry))
+	}
+
+	e := &keyCacheEntry{id: id, key: append([]byte(nil), key...), gcm: gcm}
+	e.elem = c.lru.PushFront(e)
+	c.entries[id] = e
+	return gcm, nil
+}
+
+// evict removes e and zeroizes its key copy. c.mu must be held.
+func (c *keyCache) evict(e *keyCacheEntry) {
+	c.lru.Remove(e.elem)
+	delete(c.entries, e.id)
+	clear(e.key)
+	e.gcm = nil
+}
+
+// purge evicts every entry and stops further caching, for a cache that has
+// been replaced.
+func (c *keyCache) purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.purged = true
+	for c.lru.Len() > 0 {
+		c.evict(c.lru.Back().Value.(*keyCacheEntry))
+	}
+}
+
+// len returns the number of cached keys.
+func (c *keyCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+// gcmForKey returns AES-GCM with the default nonce and tag sizes for key,
+// from the key cache when it is enabled.
+func gcmForKey(key []byte) (cipher.AEAD, error) {
+	if c := activeKeyCache.Load(); c != nil {
+		return c.gcm(key)
+	}
+	return NewGCM(key)
+}
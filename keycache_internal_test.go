@@ -0,0 +1,82 @@
+package goaes
+
+import (
+	"bytes"
+	"testing"
+)
+
+// TestKeyCache_EvictsLeastRecentlyUsed checks the size bound, LRU order and
+// zeroization of evicted key copies.
+func TestKeyCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	c := newKeyCache(2)
+	k1 := bytes.Repeat([]byte{1}, 16)
+	k2 := bytes.Repeat([]byte{2}, 24)
+	k3 := bytes.Repeat([]byte{3}, 32)
+
+	g1, err := c.gcm(k1)
+	if err != nil {
+		t.Fatalf("gcm failed: %v", err)
+	}
+	if _, err := c.gcm(k2); err != nil {
+		t.Fatalf("gcm failed: %v", err)
+	}
+	if g, _ := c.gcm(k1); g != g1 {
+		t.Fatal("expected a cache hit for k1")
+	}
+
+	e2 := c.entries[c.lru.Back().Value.(*keyCacheEntry).id]
+	if !bytes.Equal(e2.key, k2) {
+		t.Fatalf("least recently used key = %x, want k2", e2.key)
+	}
+	cached := e2.key
+
+	if _, err := c.gcm(k3); err != nil {
+		t.Fatalf("gcm failed: %v", err)
+	}
+	if c.len() != 2 {
+		t.Fatalf("cache holds %d keys, want 2", c.len())
+	}
+	if !bytes.Equal(cached, make([]byte, len(k2))) {
+		t.Fatalf("evicted key copy not zeroized: %x", cached)
+	}
+	if g, _ := c.gcm(k1); g != g1 {
+		t.Fatal("k1 should have survived eviction")
+	}
+
+	c.purge()
+	if c.len() != 0 {
+		t.Fatalf("cache holds %d keys after purge, want 0", c.len())
+	}
+}
+
+func TestKeyCache_NoInsertAfterPurge(t *testing.T) {
+	c := newKeyCache(4)
+	key := bytes.Repeat([]byte{4}, 16)
+	if _, err := c.gcm(key); err != nil {
+		t.Fatalf("gcm failed: %v", err)
+	}
+
+	// A caller that loaded the cache before it was replaced still gets a
+	// working AEAD, but its key is not cached.
+	c.purge()
+	g, err := c.gcm(key)
+	if err != nil {
+		t.Fatalf("gcm after purge failed: %v", err)
+	}
+	if g == nil {
+		t.Fatal("gcm after purge returned nil")
+	}
+	if c.len() != 0 {
+		t.Fatalf("cache holds %d keys after purge, want 0", c.len())
+	}
+}
+
+func TestKeyCache_InvalidKey(t *testing.T) {
+	c := newKeyCache(4)
+	if _, err := c.gcm([]byte("invalid-key")); err == nil {
+		t.Fatal("expected error for invalid key size")
+	}
+	if c.len() != 0 {
+		t.Fatal("invalid key must not be cached")
+	}
+}
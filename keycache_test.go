@@ -0,0 +1,115 @@
+package goaes_test
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"testing"
+
+	goaes "github.com/fawwazid/go-aes"
+)
+
+func TestKeyCache_EncryptDecrypt(t *testing.T) {
+	if err := goaes.SetKeyCacheSize(2); err != nil {
+		t.Fatalf("SetKeyCacheSize failed: %v", err)
+	}
+	defer goaes.SetKeyCacheSize(0)
+
+	plaintext := []byte("cached key schedules")
+	aad := []byte("header-aad")
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			// More keys than cache slots, so entries are evicted concurrently.
+			key := bytes.Repeat([]byte{byte(g % 3)}, 32)
+			for i := 0; i < 50; i++ {
+				ct, err := goaes.EncryptGCM(key, plaintext, aad)
+				if err != nil {
+					t.Errorf("encrypt failed: %v", err)
+					return
+				}
+				pt, err := goaes.DecryptGCM(key, ct, aad)
+				if err != nil || !bytes.Equal(pt, plaintext) {
+					t.Errorf("decrypt failed: %v", err)
+					return
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	// Output is interchangeable with the uncached path.
+	key := make([]byte, 16)
+	ct, err := goaes.EncryptGCM(key, plaintext, aad)
+	if err != nil {
+		t.Fatalf("encrypt failed: %v", err)
+	}
+	goaes.SetKeyCacheSize(0)
+	if _, err := goaes.DecryptGCM(key, ct, aad); err != nil {
+		t.Fatalf("uncached decrypt of cached output failed: %v", err)
+	}
+
+	if err := goaes.SetKeyCacheSize(-1); err == nil {
+		t.Error("expected error for negative cache size")
+	}
+}
+
+func BenchmarkEncryptGCM(b *testing.B) {
+	key := make([]byte, 32)
+	aad := []byte("header-aad")
+
+	for _, cached := range []bool{false, true} {
+		for _, size := range []int{64, 256, 1024, 4096} {
+			name := fmt.Sprintf("uncached/%dB", size)
+			if cached {
+				name = fmt.Sprintf("cached/%dB", size)
+			}
+			b.Run(name, func(b *testing.B) {
+				if cached {
+					goaes.SetKeyCacheSize(16)
+					defer goaes.SetKeyCacheSize(0)
+				}
+				plaintext := make([]byte, size)
+				b.SetBytes(int64(size))
+				for b.Loop() {
+					if _, err := goaes.EncryptGCM(key, plaintext, aad); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkDecryptGCM(b *testing.B) {
+	key := make([]byte, 32)
+	aad := []byte("header-aad")
+
+	for _, cached := range []bool{false, true} {
+		for _, size := range []int{64, 256, 1024, 4096} {
+			name := fmt.Sprintf("uncached/%dB", size)
+			if cached {
+				name = fmt.Sprintf("cached/%dB", size)
+			}
+			b.Run(name, func(b *testing.B) {
+				ct, err := goaes.EncryptGCM(key, make([]byte, size), aad)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if cached {
+					goaes.SetKeyCacheSize(16)
+					defer goaes.SetKeyCacheSize(0)
+				}
+				b.SetBytes(int64(size))
+				for b.Loop() {
+					if _, err := goaes.DecryptGCM(key, ct, aad); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package goaes
+
+import (
+	"errors"
+	"sync"
+)
+
+// DefaultMaxInvocations is the NIST SP 800-38D, Section 8.3 limit on the
+// number of encryptions under one key with random 96-bit GCM nonces.
+const DefaultMaxInvocations = 1 << 32
+
+// ErrKeyExhausted is returned by a KeyHandle once an encryption would cross
+// one of its usage limits and no rotation hook replaced the key.
+var ErrKeyExhausted = errors.New("key usage limit reached")
+
+// KeyUsage reports how much a key has been used for encryption.
+type KeyUsage struct {
+	// Invocations is the number of messages encrypted.
+	Invocations uint64
+	// Bytes is the total plaintext length encrypted.
+	Bytes uint64
+}
+
+// KeyPolicy configures the usage limits of a KeyHandle.
+type KeyPolicy struct {
+	// MaxInvocations is the maximum number of encryptions.
+	// Zero means DefaultMaxInvocations.
+	MaxInvocations uint64
+	// MaxBytes is the maximum total plaintext length. Zero means no limit.
+	MaxBytes uint64
+	// Rotate, if set, is called when an encryption would cross a limit. It
+	// receives a copy of the retiring key (e.g. to keep for decryption) and
+	// its final usage, and returns the replacement key.
+	// Usage is reset and the encryption proceeds under the new key. If it
+	// returns an error, the encryption fails with that error.
+	Rotate func(old []byte, usage KeyUsage) ([]byte, error)
+}
+
+// KeyHandle is a stateful key that counts its encryptions and enforces the
+// limits of its KeyPolicy, for the random-nonce modes GCM and CTR.
+// It is safe for concurrent use.
+//
+// Decryption is not counted and always uses the current key, so messages
+// encrypted before a rotation must be decrypted with the retired key.
+type KeyHandle struct {
+	mu     sync.Mutex
+	key    []byte
+	policy KeyPolicy
+	usage  KeyUsage
+}
+
+// NewKeyHandle returns a KeyHandle holding a copy of key.
+//
+// Parameters:
+//   - key: 16/24/32 bytes (AES-128/192/256).
+//   - policy: usage limits and optional rotation hook.
+func NewKeyHandle(key []byte, policy KeyPolicy) (*KeyHandle, error) {
+	if err := validateKeySize(key); err != nil {
+		return nil, err
+	}
+	if policy.MaxInvocations == 0 {
+		policy.MaxInvocations = DefaultMaxInvocations
+	}
+	return &KeyHandle{key: append([]byte(nil), key...), policy: policy}, nil
+}
+
+// Usage returns the usage of the current key.
+func (k *KeyHandle) Usage() KeyUsage {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.usage
+}
+
+// EncryptGCM is EncryptGCM under the handle's key, counted against its limits.
+func (k *KeyHandle) EncryptGCM(plaintext, aad []byte) ([]byte, error) {
+	key, err := k.reserve(len(plaintext))
+	if err != nil {
+		return nil, err
+	}
+	return EncryptGCM(key, plaintext, aad)
+}
+
+// DecryptGCM is DecryptGCM under the handle's current key.
+func (k *KeyHandle) DecryptGCM(ciphertext, aad []byte) ([]byte, error) {
+	return DecryptGCM(k.current(), ciphertext, aad)
+}
+
+// EncryptCTR is EncryptCTR under the handle's key, counted against its limits.
+func (k *KeyHandle) EncryptCTR(plaintext []byte) ([]byte, error) {
+	key, err := k.reserve(len(plaintext))
+	if err != nil {
+		return nil, err
+	}
+	return EncryptCTR(key, plaintext)
+}
+
+// DecryptCTR is DecryptCTR under the handle's current key.
+func (k *KeyHandle) DecryptCTR(ciphertext []byte) ([]byte, error) {
+	return DecryptCTR(k.current(), ciphertext)
+}
+
+// current returns the key in use.
+func (k *KeyHandle) current() []byte {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.key
+}
+
+// reserve counts one encryption of n bytes and returns the key to use,
+// rotating it first if the policy allows.
+func (k *KeyHandle) reserve(n int) ([]byte, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if k.exceeds(n) {
+		if k.policy.Rotate == nil {
+			return nil, ErrKeyExhausted
+		}
+		// The old key is dropped rather than cleared, since concurrent
+		// calls may still be encrypting with it.
+		next, err := k.policy.Rotate(append([]byte(nil), k.key...), k.usage)
+		if err != nil {
+			return nil, err
+		}
+		if err := validateKeySize(next); err != nil {
+			return nil, err
+		}
+		k.key = append([]byte(nil), next...)
+		k.usage = KeyUsage{}
+
+		if k.exceeds(n) {
+			return nil, ErrKeyExhausted
+		}
+	}
+
+	k.usage.Invocations++
+	k.usage.Bytes += uint64(n)
+	return k.key, nil
+}
+
+// exceeds reports whether one more encryption of n bytes crosses a limit.
+func (k *KeyHandle) exceeds(n int) bool {
+	if k.usage.Invocations >= k.policy.MaxInvocations {
+		return true
+	}
+	return k.policy.MaxBytes != 0 && uint64(n) > k.policy.MaxBytes-k.usage.Bytes
+}
@@ -0,0 +1,163 @@
+package goaes_test
+
+import (
+	"bytes"
+	"errors"
+	"sync"
+	"testing"
+
+	goaes "github.com/fawwazid/go-aes"
+)
+
+func TestKeyHandle_EncryptDecrypt(t *testing.T) {
+	key := make([]byte, 32)
+	plaintext := []byte("counted encryptions")
+	aad := []byte("header-aad")
+
+	h, err := goaes.NewKeyHandle(key, goaes.KeyPolicy{})
+	if err != nil {
+		t.Fatalf("NewKeyHandle failed: %v", err)
+	}
+
+	ct, err := h.EncryptGCM(plaintext, aad)
+	if err != nil {
+		t.Fatalf("EncryptGCM failed: %v", err)
+	}
+	pt, err := goaes.DecryptGCM(key, ct, aad)
+	if err != nil || !bytes.Equal(pt, plaintext) {
+		t.Fatalf("DecryptGCM of handle output failed: %v", err)
+	}
+	if pt, err := h.DecryptGCM(ct, aad); err != nil || !bytes.Equal(pt, plaintext) {
+		t.Fatalf("handle DecryptGCM failed: %v", err)
+	}
+
+	ct, err = h.EncryptCTR(plaintext)
+	if err != nil {
+		t.Fatalf("EncryptCTR failed: %v", err)
+	}
+	if pt, err := h.DecryptCTR(ct); err != nil || !bytes.Equal(pt, plaintext) {
+		t.Fatalf("handle DecryptCTR failed: %v", err)
+	}
+
+	want := goaes.KeyUsage{Invocations: 2, Bytes: uint64(2 * len(plaintext))}
+	if got := h.Usage(); got != want {
+		t.Fatalf("usage = %+v, want %+v", got, want)
+	}
+
+	if _, err := goaes.NewKeyHandle([]byte("invalid-key"), goaes.KeyPolicy{}); err == nil {
+		t.Error("expected error for invalid key size in NewKeyHandle")
+	}
+}
+
+func TestKeyHandle_Limits(t *testing.T) {
+	key := make([]byte, 16)
+
+	h, err := goaes.NewKeyHandle(key, goaes.KeyPolicy{MaxInvocations: 3})
+	if err != nil {
+		t.Fatalf("NewKeyHandle failed: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := h.EncryptGCM([]byte("msg"), nil); err != nil {
+			t.Fatalf("encryption %d failed: %v", i, err)
+		}
+	}
+	if _, err := h.EncryptCTR([]byte("msg")); !errors.Is(err, goaes.ErrKeyExhausted) {
+		t.Fatalf("error = %v, want ErrKeyExhausted", err)
+	}
+
+	h, err = goaes.NewKeyHandle(key, goaes.KeyPolicy{MaxBytes: 10})
+	if err != nil {
+		t.Fatalf("NewKeyHandle failed: %v", err)
+	}
+	if _, err := h.EncryptGCM(make([]byte, 6), nil); err != nil {
+		t.Fatalf("encryption within byte limit failed: %v", err)
+	}
+	if _, err := h.EncryptGCM(make([]byte, 5), nil); !errors.Is(err, goaes.ErrKeyExhausted) {
+		t.Fatalf("error = %v, want ErrKeyExhausted", err)
+	}
+	if _, err := h.EncryptGCM(make([]byte, 4), nil); err != nil {
+		t.Fatalf("encryption filling byte limit failed: %v", err)
+	}
+	if got := h.Usage(); got.Bytes != 10 || got.Invocations != 2 {
+		t.Fatalf("usage = %+v, want 2 invocations and 10 bytes", got)
+	}
+}
+
+func TestKeyHandle_Rotate(t *testing.T) {
+	oldKey := bytes.Repeat([]byte{0x01}, 32)
+	newKey := bytes.Repeat([]byte{0x02}, 32)
+
+	var retired []byte
+	var retiredUsage goaes.KeyUsage
+	h, err := goaes.NewKeyHandle(oldKey, goaes.KeyPolicy{
+		MaxInvocations: 2,
+		Rotate: func(old []byte, usage goaes.KeyUsage) ([]byte, error) {
+			retired, retiredUsage = old, usage
+			return newKey, nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewKeyHandle failed: %v", err)
+	}
+
+	var cts [][]byte
+	for i := 0; i < 3; i++ {
+		ct, err := h.EncryptGCM([]byte("msg"), nil)
+		if err != nil {
+			t.Fatalf("encryption %d failed: %v", i, err)
+		}
+		cts = append(cts, ct)
+	}
+
+	if !bytes.Equal(retired, oldKey) || retiredUsage.Invocations != 2 {
+		t.Fatalf("rotate hook got key %x usage %+v", retired, retiredUsage)
+	}
+	if got := h.Usage(); got.Invocations != 1 {
+		t.Fatalf("usage after rotation = %+v, want 1 invocation", got)
+	}
+	if _, err := goaes.DecryptGCM(oldKey, cts[1], nil); err != nil {
+		t.Fatalf("pre-rotation ciphertext should open under old key: %v", err)
+	}
+	if _, err := goaes.DecryptGCM(newKey, cts[2], nil); err != nil {
+		t.Fatalf("post-rotation ciphertext should open under new key: %v", err)
+	}
+
+	// A failing hook fails the encryption.
+	hookErr := errors.New("rotation unavailable")
+	h, _ = goaes.NewKeyHandle(oldKey, goaes.KeyPolicy{
+		MaxInvocations: 1,
+		Rotate:         func([]byte, goaes.KeyUsage) ([]byte, error) { return nil, hookErr },
+	})
+	h.EncryptGCM(nil, nil)
+	if _, err := h.EncryptGCM(nil, nil); !errors.Is(err, hookErr) {
+		t.Fatalf("error = %v, want hook error", err)
+	}
+}
+
+func TestKeyHandle_Concurrent(t *testing.T) {
+	const goroutines, perGoroutine = 8, 50
+
+	h, err := goaes.NewKeyHandle(make([]byte, 32), goaes.KeyPolicy{MaxInvocations: goroutines * perGoroutine})
+	if err != nil {
+		t.Fatalf("NewKeyHandle failed: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < perGoroutine; j++ {
+				if _, err := h.EncryptCTR([]byte("msg")); err != nil {
+					t.Errorf("encryption failed: %v", err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if _, err := h.EncryptCTR([]byte("msg")); !errors.Is(err, goaes.ErrKeyExhausted) {
+		t.Fatalf("error = %v, want ErrKeyExhausted", err)
+	}
+}
@@ -0,0 +1,201 @@
+package goaes
+
+import (
+	"crypto/cipher"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+)
+
+// ErrKeyWrapIntegrity is returned by UnwrapKey and UnwrapKeyPadded when the
+// wrapped key fails its integrity check, either because it was modified or
+// because the wrong KEK was used.
+var ErrKeyWrapIntegrity = errors.New("key unwrap: integrity check failed")
+
+var (
+	// kwIV is the default initial value for KW (RFC 3394, Section 2.2.3.1).
+	kwIV = [8]byte{0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6}
+	// kwpICV is the 32-bit constant prefix of the KWP alternative initial value (RFC 5649, Section 3).
+	kwpICV = [4]byte{0xa6, 0x59, 0x59, 0xa6}
+)
+
+// WrapKey wraps key material under a key-encryption key using AES-KW.
+//
+// NIST SP 800-38F / RFC 3394: Deterministic authenticated encryption for keys.
+// Suitable for storing keys from GenerateAESKey under a long-term KEK.
+//
+// Parameters:
+//   - kek: 16, 24, or 32 bytes (AES-128, 192, or 256).
+//   - key: key material to wrap; at least 16 bytes and a multiple of 8 bytes.
+//
+// Returns: wrapped key (8 bytes longer than key).
+func WrapKey(kek, key []byte) ([]byte, error) {
+	block, err := newCipherBlock(kek)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(key) < 16 || len(key)%8 != 0 {
+		return nil, errors.New("key to wrap must be at least 16 bytes and a multiple of 8 bytes")
+	}
+
+	return kwWrap(block, kwIV, key), nil
+}
+
+// UnwrapKey unwraps key material produced by WrapKey.
+//
+// Parameters:
+//   - kek: same key-encryption key used for wrapping.
+//   - wrapped: wrapped key.
+//
+// Returns: unwrapped key, or ErrKeyWrapIntegrity if the integrity check fails.
+func UnwrapKey(kek, wrapped []byte) ([]byte, error) {
+	block, err := newCipherBlock(kek)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(wrapped) < 24 || len(wrapped)%8 != 0 {
+		return nil, errors.New("wrapped key must be at least 24 bytes and a multiple of 8 bytes")
+	}
+
+	a, key := kwUnwrap(block, wrapped)
+	if subtle.ConstantTimeCompare(a[:], kwIV[:]) != 1 {
+		clear(key)
+		return nil, ErrKeyWrapIntegrity
+	}
+	return key, nil
+}
+
+// WrapKeyPadded wraps key material of any length using AES-KWP (key wrap with padding).
+//
+// NIST SP 800-38F / RFC 5649: Use this for secrets that are not a multiple of
+// 8 bytes, such as encoded private keys.
+//
+// Parameters:
+//   - kek: 16, 24, or 32 bytes (AES-128, 192, or 256).
+//   - key: key material to wrap (1 byte to 2^32-1 bytes).
+//
+// Returns: wrapped key (padded to a multiple of 8 bytes, plus 8 bytes).
+func WrapKeyPadded(kek, key []byte) ([]byte, error) {
+	block, err := newCipherBlock(kek)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(key) == 0 || uint64(len(key)) > 0xffffffff {
+		return nil, errors.New("key to wrap must be between 1 and 2^32-1 bytes")
+	}
+
+	var aiv [8]byte
+	copy(aiv[:4], kwpICV[:])
+	binary.BigEndian.PutUint32(aiv[4:], uint32(len(key)))
+
+	padded := make([]byte, (len(key)+7)/8*8)
+	copy(padded, key)
+	defer clear(padded)
+
+	if len(padded) == 8 {
+		out := make([]byte, 16)
+		copy(out, aiv[:])
+		copy(out[8:], padded)
+		block.Encrypt(out, out)
+		return out, nil
+	}
+	return kwWrap(block, aiv, padded), nil
+}
+
+// UnwrapKeyPadded unwraps key material produced by WrapKeyPadded.
+//
+// Parameters:
+//   - kek: same key-encryption key used for wrapping.
+//   - wrapped: wrapped key.
+//
+// Returns: unwrapped key, or ErrKeyWrapIntegrity if the integrity check fails.
+func UnwrapKeyPadded(kek, wrapped []byte) ([]byte, error) {
+	block, err := newCipherBlock(kek)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(wrapped) < 16 || len(wrapped)%8 != 0 {
+		return nil, errors.New("wrapped key must be at least 16 bytes and a multiple of 8 bytes")
+	}
+
+	var a [8]byte
+	var padded []byte
+	if len(wrapped) == 16 {
+		buf := make([]byte, 16)
+		block.Decrypt(buf, wrapped)
+		copy(a[:], buf[:8])
+		padded = buf[8:]
+	} else {
+		a, padded = kwUnwrap(block, wrapped)
+	}
+
+	// Check the ICV, the message length indicator and the zero padding.
+	mli := uint64(binary.BigEndian.Uint32(a[4:]))
+	n := uint64(len(padded))
+	valid := subtle.ConstantTimeCompare(a[:4], kwpICV[:]) == 1 && mli <= n && mli+8 > n
+	if valid {
+		var nonZero byte
+		for _, b := range padded[mli:] {
+			nonZero |= b
+		}
+		valid = nonZero == 0
+	}
+
+	if !valid {
+		clear(padded)
+		return nil, ErrKeyWrapIntegrity
+	}
+	return padded[:mli], nil
+}
+
+// kwWrap implements the wrapping function W (SP 800-38F, Algorithm 1) with
+// initial value iv over the semiblocks of p.
+func kwWrap(block cipher.Block, iv [8]byte, p []byte) []byte {
+	n := len(p) / 8
+	out := make([]byte, 8+len(p))
+	copy(out[8:], p)
+
+	var b [16]byte
+	copy(b[:8], iv[:])
+	for j := 0; j < 6; j++ {
+		for i := 1; i <= n; i++ {
+			r := out[i*8 : i*8+8]
+			copy(b[8:], r)
+			block.Encrypt(b[:], b[:])
+			t := uint64(n*j + i)
+			binary.BigEndian.PutUint64(b[:8], binary.BigEndian.Uint64(b[:8])^t)
+			copy(r, b[8:])
+		}
+	}
+	copy(out[:8], b[:8])
+	return out
+}
+
+// kwUnwrap implements the unwrapping function W^-1 (SP 800-38F, Algorithm 2).
+// It returns the recovered initial value and the unwrapped semiblocks.
+func kwUnwrap(block cipher.Block, c []byte) ([8]byte, []byte) {
+	n := len(c)/8 - 1
+	out := make([]byte, len(c)-8)
+	copy(out, c[8:])
+
+	var b [16]byte
+	copy(b[:8], c[:8])
+	for j := 5; j >= 0; j-- {
+		for i := n; i >= 1; i-- {
+			r := out[(i-1)*8 : i*8]
+			t := uint64(n*j + i)
+			binary.BigEndian.PutUint64(b[:8], binary.BigEndian.Uint64(b[:8])^t)
+			copy(b[8:], r)
+			block.Decrypt(b[:], b[:])
+			copy(r, b[8:])
+		}
+	}
+
+	var a [8]byte
+	copy(a[:], b[:8])
+	return a, out
+}
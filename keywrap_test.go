@@ -0,0 +1,179 @@
+package goaes_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	goaes "github.com/fawwazid/go-aes"
+)
+
+func TestKeyWrap_WrapUnwrap(t *testing.T) {
+	for _, bits := range []int{128, 192, 256} {
+		kek, err := goaes.GenerateAESKey(bits)
+		if err != nil {
+			t.Fatalf("kek generation failed: %v", err)
+		}
+		key, err := goaes.GenerateAESKey(256)
+		if err != nil {
+			t.Fatalf("key generation failed: %v", err)
+		}
+
+		wrapped, err := goaes.WrapKey(kek, key)
+		if err != nil {
+			t.Fatalf("wrap failed for kek bits %d: %v", bits, err)
+		}
+		if len(wrapped) != len(key)+8 {
+			t.Fatalf("wrapped len = %d, want %d", len(wrapped), len(key)+8)
+		}
+
+		got, err := goaes.UnwrapKey(kek, wrapped)
+		if err != nil {
+			t.Fatalf("unwrap failed for kek bits %d: %v", bits, err)
+		}
+		if !bytes.Equal(got, key) {
+			t.Fatalf("key mismatch for kek bits %d", bits)
+		}
+
+		// tamper detection: flip last byte
+		bad := make([]byte, len(wrapped))
+		copy(bad, wrapped)
+		bad[len(bad)-1] ^= 0xFF
+		if _, err := goaes.UnwrapKey(kek, bad); !errors.Is(err, goaes.ErrKeyWrapIntegrity) {
+			t.Fatalf("expected ErrKeyWrapIntegrity for tampered key (kek bits %d), got %v", bits, err)
+		}
+	}
+}
+
+func TestKeyWrap_Padded(t *testing.T) {
+	kek := make([]byte, 32)
+	for _, n := range []int{1, 7, 8, 9, 16, 20, 33, 1217} {
+		key := bytes.Repeat([]byte{0x5a}, n)
+
+		wrapped, err := goaes.WrapKeyPadded(kek, key)
+		if err != nil {
+			t.Fatalf("wrap failed for len %d: %v", n, err)
+		}
+
+		got, err := goaes.UnwrapKeyPadded(kek, wrapped)
+		if err != nil {
+			t.Fatalf("unwrap failed for len %d: %v", n, err)
+		}
+		if !bytes.Equal(got, key) {
+			t.Fatalf("key mismatch for len %d", n)
+		}
+
+		bad := make([]byte, len(wrapped))
+		copy(bad, wrapped)
+		bad[0] ^= 0x01
+		if _, err := goaes.UnwrapKeyPadded(kek, bad); !errors.Is(err, goaes.ErrKeyWrapIntegrity) {
+			t.Fatalf("expected ErrKeyWrapIntegrity for tampered key (len %d), got %v", n, err)
+		}
+	}
+
+	// KW output must not be accepted by KWP.
+	wrapped, err := goaes.WrapKey(kek, make([]byte, 16))
+	if err != nil {
+		t.Fatalf("wrap failed: %v", err)
+	}
+	if _, err := goaes.UnwrapKeyPadded(kek, wrapped); !errors.Is(err, goaes.ErrKeyWrapIntegrity) {
+		t.Fatalf("expected ErrKeyWrapIntegrity for KW input to KWP, got %v", err)
+	}
+}
+
+func TestKeyWrap_InvalidInput(t *testing.T) {
+	kek := make([]byte, 16)
+
+	if _, err := goaes.WrapKey([]byte("invalid-key"), make([]byte, 16)); err == nil {
+		t.Error("expected error for invalid KEK size in WrapKey")
+	}
+	for _, n := range []int{0, 8, 17} {
+		if _, err := goaes.WrapKey(kek, make([]byte, n)); err == nil {
+			t.Errorf("expected error for key len %d in WrapKey", n)
+		}
+	}
+	if _, err := goaes.UnwrapKey(kek, make([]byte, 16)); err == nil {
+		t.Error("expected error for short input in UnwrapKey")
+	}
+	if _, err := goaes.WrapKeyPadded(kek, nil); err == nil {
+		t.Error("expected error for empty key in WrapKeyPadded")
+	}
+	if _, err := goaes.UnwrapKeyPadded(kek, make([]byte, 12)); err == nil {
+		t.Error("expected error for invalid length in UnwrapKeyPadded")
+	}
+}
+
+// TestKeyWrap_Vectors checks the RFC 3394 Section 4 and RFC 5649 Section 6 vectors.
+func TestKeyWrap_Vectors(t *testing.T) {
+	tests := []struct {
+		name, kek, key, wrapped string
+		padded                  bool
+	}{
+		{
+			name:    "RFC 3394 4.1 128-bit KEK",
+			kek:     "000102030405060708090a0b0c0d0e0f",
+			key:     "00112233445566778899aabbccddeeff",
+			wrapped: "1fa68b0a8112b447aef34bd8fb5a7b829d3e862371d2cfe5",
+		},
+		{
+			name:    "RFC 3394 4.2 192-bit KEK",
+			kek:     "000102030405060708090a0b0c0d0e0f1011121314151617",
+			key:     "00112233445566778899aabbccddeeff",
+			wrapped: "96778b25ae6ca435f92b5b97c050aed2468ab8a17ad84e5d",
+		},
+		{
+			name:    "RFC 3394 4.3 256-bit KEK",
+			kek:     "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f",
+			key:     "00112233445566778899aabbccddeeff",
+			wrapped: "64e8c3f9ce0f5ba263e9777905818a2a93c8191e7d6e8ae7",
+		},
+		{
+			name:    "RFC 3394 4.6 256-bit KEK, 256-bit key",
+			kek:     "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f",
+			key:     "00112233445566778899aabbccddeeff000102030405060708090a0b0c0d0e0f",
+			wrapped: "28c9f404c4b810f4cbccb35cfb87f8263f5786e2d80ed326cbc7f0e71a99f43bfb988b9b7a02dd21",
+		},
+		{
+			name:    "RFC 5649 20-byte key",
+			kek:     "5840df6e29b02af1ab493b705bf16ea1ae8338f4dcc176a8",
+			key:     "c37b7e6492584340bed12207808941155068f738",
+			wrapped: "138bdeaa9b8fa7fc61f97742e72248ee5ae6ae5360d1ae6a5f54f373fa543b6a",
+			padded:  true,
+		},
+		{
+			name:    "RFC 5649 7-byte key",
+			kek:     "5840df6e29b02af1ab493b705bf16ea1ae8338f4dcc176a8",
+			key:     "466f7250617369",
+			wrapped: "afbeb0f07dfbf5419200f2ccb50bb24f",
+			padded:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kek := mustHex(t, tt.kek)
+			key := mustHex(t, tt.key)
+
+			wrap, unwrap := goaes.WrapKey, goaes.UnwrapKey
+			if tt.padded {
+				wrap, unwrap = goaes.WrapKeyPadded, goaes.UnwrapKeyPadded
+			}
+
+			wrapped, err := wrap(kek, key)
+			if err != nil {
+				t.Fatalf("wrap failed: %v", err)
+			}
+			if !bytes.Equal(wrapped, mustHex(t, tt.wrapped)) {
+				t.Fatalf("wrapped = %x, want %s", wrapped, tt.wrapped)
+			}
+
+			got, err := unwrap(kek, wrapped)
+			if err != nil {
+				t.Fatalf("unwrap failed: %v", err)
+			}
+			if !bytes.Equal(got, key) {
+				t.Fatalf("key = %x, want %s", got, tt.key)
+			}
+		})
+	}
+}
@@ -0,0 +1,240 @@
+package goaes
+
+import (
+	"crypto/cipher"
+	"crypto/subtle"
+	"errors"
+	"math/bits"
+)
+
+const (
+	ocbNonceSize = 12
+	// ocbMaxL is the number of precomputed L_i values, enough for 2^64 blocks.
+	ocbMaxL = 64
+)
+
+// EncryptOCB encrypts plaintext using AES-OCB3 (RFC 7253) with a 128-bit tag.
+//
+// OCB is a single-pass AEAD: each block is processed by one AES call, which
+// makes it faster than GCM on many platforms. It is required by OpenPGP v6.
+// Use NewOCB for 64- or 96-bit tags.
+//
+// Parameters:
+//   - key: 16/24/32 bytes (AES-128/192/256).
+//   - plaintext: Data to be encrypted.
+//   - aad: Additional Authenticated Data (optional, can be nil).
+//
+// Returns: nonce||ciphertext
+func EncryptOCB(key, plaintext, aad []byte) ([]byte, error) {
+	return SealOCB(nil, key, plaintext, aad)
+}
+
+// DecryptOCB decrypts data produced by EncryptOCB.
+// It expects the nonce to be prepended to the ciphertext.
+//
+// Parameters:
+//   - key: same key used for encryption.
+//   - ciphertext: nonce||ciphertext.
+//   - aad: same additional data used for encryption.
+//
+// Returns: decrypted plaintext.
+func DecryptOCB(key, ciphertext, aad []byte) ([]byte, error) {
+	return OpenOCB(nil, key, ciphertext, aad)
+}
+
+// SealOCB is the append-style form of EncryptOCB: it appends
+// nonce||ciphertext to dst and returns the updated slice. dst may overlap
+// plaintext, e.g. buf[:0] to encrypt buf in place; aad must not overlap dst.
+func SealOCB(dst, key, plaintext, aad []byte) ([]byte, error) {
+	aead, err := newOCB(key, 16)
+	if err != nil {
+		return nil, err
+	}
+	return sealPrefixed(dst, aead, plaintext, aad)
+}
+
+// OpenOCB is the append-style form of DecryptOCB: it appends the plaintext
+// to dst and returns the updated slice. dst may overlap ciphertext, e.g.
+// buf[:0] to decrypt buf in place.
+func OpenOCB(dst, key, ciphertext, aad []byte) ([]byte, error) {
+	aead, err := newOCB(key, 16)
+	if err != nil {
+		return nil, err
+	}
+	return openPrefixed(dst, aead, ciphertext, aad)
+}
+
+// NewOCB returns AES-OCB3 as a cipher.AEAD with a 96-bit nonce.
+// The caller is responsible for never reusing a nonce.
+//
+// Parameters:
+//   - key: 16/24/32 bytes (AES-128/192/256).
+//   - tagSize: 8, 12 or 16 bytes (64, 96 or 128 bits).
+func NewOCB(key []byte, tagSize int) (cipher.AEAD, error) {
+	return newOCB(key, tagSize)
+}
+
+// ocb implements cipher.AEAD for AES-OCB3.
+type ocb struct {
+	block   cipher.Block
+	tagSize int
+	lStar   [16]byte
+	lDollar [16]byte
+	l       [ocbMaxL][16]byte
+}
+
+// newOCB validates the key and tag size and precomputes the L table.
+func newOCB(key []byte, tagSize int) (*ocb, error) {
+	if tagSize != 8 && tagSize != 12 && tagSize != 16 {
+		return nil, errors.New("invalid OCB tag size: must be 8, 12, or 16 bytes")
+	}
+
+	block, err := newCipherBlock(key)
+	if err != nil {
+		return nil, err
+	}
+
+	o := &ocb{block: block, tagSize: tagSize}
+	block.Encrypt(o.lStar[:], o.lStar[:])
+	o.lDollar = gfDouble(&o.lStar)
+	o.l[0] = gfDouble(&o.lDollar)
+	for i := 1; i < ocbMaxL; i++ {
+		o.l[i] = gfDouble(&o.l[i-1])
+	}
+	return o, nil
+}
+
+func (o *ocb) NonceSize() int { return ocbNonceSize }
+
+func (o *ocb) Overhead() int { return o.tagSize }
+
+func (o *ocb) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
+	if len(nonce) != ocbNonceSize {
+		panic("goaes: incorrect nonce length given to OCB")
+	}
+
+	ret, out := sliceForAppend(dst, len(plaintext)+o.tagSize)
+	tag := o.crypt(true, nonce, out[:len(plaintext)], plaintext, additionalData)
+	copy(out[len(plaintext):], tag[:o.tagSize])
+	return ret
+}
+
+func (o *ocb) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(nonce) != ocbNonceSize {
+		panic("goaes: incorrect nonce length given to OCB")
+	}
+	if len(ciphertext) < o.tagSize {
+		return nil, errOpen
+	}
+
+	ctLen := len(ciphertext) - o.tagSize
+	var tag [16]byte
+	copy(tag[:], ciphertext[ctLen:])
+
+	ret, out := sliceForAppend(dst, ctLen)
+	expected := o.crypt(false, nonce, out, ciphertext[:ctLen], additionalData)
+	if subtle.ConstantTimeCompare(expected[:o.tagSize], tag[:o.tagSize]) != 1 {
+		clear(out)
+		return nil, errOpen
+	}
+	return ret, nil
+}
+
+// crypt encrypts or decrypts src into dst and returns the full 16-byte tag
+// (RFC 7253, Sections 4.2 and 4.3).
+func (o *ocb) crypt(encrypt bool, nonce, dst, src, additionalData []byte) [16]byte {
+	offset := o.initialOffset(nonce)
+
+	var checksum, tmp [16]byte
+	i := 1
+	for ; len(src) >= 16; i++ {
+		subtle.XORBytes(offset[:], offset[:], o.l[bits.TrailingZeros(uint(i))][:])
+		subtle.XORBytes(tmp[:], src[:16], offset[:])
+		if encrypt {
+			subtle.XORBytes(checksum[:], checksum[:], src[:16])
+			o.block.Encrypt(tmp[:], tmp[:])
+		} else {
+			o.block.Decrypt(tmp[:], tmp[:])
+		}
+		subtle.XORBytes(dst[:16], tmp[:], offset[:])
+		if !encrypt {
+			subtle.XORBytes(checksum[:], checksum[:], dst[:16])
+		}
+		src = src[16:]
+		dst = dst[16:]
+	}
+
+	if len(src) > 0 {
+		subtle.XORBytes(offset[:], offset[:], o.lStar[:])
+		var pad [16]byte
+		o.block.Encrypt(pad[:], offset[:])
+		if encrypt {
+			subtle.XORBytes(checksum[:], checksum[:], src)
+			checksum[len(src)] ^= 0x80
+		}
+		subtle.XORBytes(dst, src, pad[:])
+		if !encrypt {
+			subtle.XORBytes(checksum[:], checksum[:], dst[:len(src)])
+			checksum[len(src)] ^= 0x80
+		}
+	}
+
+	var tag [16]byte
+	subtle.XORBytes(tag[:], checksum[:], offset[:])
+	subtle.XORBytes(tag[:], tag[:], o.lDollar[:])
+	o.block.Encrypt(tag[:], tag[:])
+
+	h := o.hash(additionalData)
+	subtle.XORBytes(tag[:], tag[:], h[:])
+	return tag
+}
+
+// initialOffset derives Offset_0 from the nonce (RFC 7253, Section 4.2).
+func (o *ocb) initialOffset(nonce []byte) [16]byte {
+	var n [16]byte
+	n[0] = byte((o.tagSize * 8 % 128) << 1)
+	n[15-len(nonce)] |= 1
+	copy(n[16-len(nonce):], nonce)
+
+	bottom := uint(n[15] & 0x3f)
+	n[15] &= 0xc0
+
+	var stretch [24]byte
+	o.block.Encrypt(stretch[:16], n[:])
+	subtle.XORBytes(stretch[16:], stretch[:8], stretch[1:9])
+
+	// Offset_0 = Stretch[1+bottom..128+bottom] (bit indices).
+	var offset [16]byte
+	byteShift, bitShift := bottom/8, bottom%8
+	for i := range offset {
+		offset[i] = stretch[i+int(byteShift)] << bitShift
+		if bitShift != 0 {
+			offset[i] |= stretch[i+int(byteShift)+1] >> (8 - bitShift)
+		}
+	}
+	return offset
+}
+
+// hash computes HASH(K, A) over the associated data (RFC 7253, Section 4.1).
+func (o *ocb) hash(additionalData []byte) [16]byte {
+	var sum, offset, tmp [16]byte
+	i := 1
+	for ; len(additionalData) >= 16; i++ {
+		subtle.XORBytes(offset[:], offset[:], o.l[bits.TrailingZeros(uint(i))][:])
+		subtle.XORBytes(tmp[:], additionalData[:16], offset[:])
+		o.block.Encrypt(tmp[:], tmp[:])
+		subtle.XORBytes(sum[:], sum[:], tmp[:])
+		additionalData = additionalData[16:]
+	}
+
+	if len(additionalData) > 0 {
+		subtle.XORBytes(offset[:], offset[:], o.lStar[:])
+		tmp = [16]byte{}
+		copy(tmp[:], additionalData)
+		tmp[len(additionalData)] = 0x80
+		subtle.XORBytes(tmp[:], tmp[:], offset[:])
+		o.block.Encrypt(tmp[:], tmp[:])
+		subtle.XORBytes(sum[:], sum[:], tmp[:])
+	}
+	return sum
+}
@@ -0,0 +1,133 @@
+package goaes_test
+
+import (
+	"bytes"
+	"testing"
+
+	goaes "github.com/fawwazid/go-aes"
+)
+
+func TestAESOCB_EncryptDecrypt(t *testing.T) {
+	plaintext := []byte("Amazingly few discotheques provide jukeboxes")
+	aad := []byte("header-aad")
+
+	for _, k := range []int{16, 24, 32} {
+		key := make([]byte, k)
+		for i := 0; i < k; i++ {
+			key[i] = byte(i)
+		}
+
+		ct, err := goaes.EncryptOCB(key, plaintext, aad)
+		if err != nil {
+			t.Fatalf("encrypt failed for key len %d: %v", k, err)
+		}
+
+		pt, err := goaes.DecryptOCB(key, ct, aad)
+		if err != nil {
+			t.Fatalf("decrypt failed for key len %d: %v", k, err)
+		}
+
+		if !bytes.Equal(pt, plaintext) {
+			t.Fatalf("plaintext mismatch for key len %d", k)
+		}
+
+		// tamper detection: flip last byte
+		bad := make([]byte, len(ct))
+		copy(bad, ct)
+		bad[len(bad)-1] ^= 0xFF
+		_, err = goaes.DecryptOCB(key, bad, aad)
+		if err == nil {
+			t.Fatalf("expected decryption error for tampered ciphertext (key len %d)", k)
+		}
+	}
+}
+
+func TestAESOCB_InvalidParams(t *testing.T) {
+	_, err := goaes.EncryptOCB([]byte("invalid-key"), []byte("secret"), nil)
+	if err == nil {
+		t.Error("expected error for invalid key size in EncryptOCB")
+	}
+	_, err = goaes.DecryptOCB(make([]byte, 16), []byte("short"), nil)
+	if err == nil {
+		t.Error("expected error for short ciphertext in DecryptOCB")
+	}
+	for _, tag := range []int{0, 4, 10, 17} {
+		if _, err := goaes.NewOCB(make([]byte, 16), tag); err == nil {
+			t.Errorf("expected error for tag size %d", tag)
+		}
+	}
+}
+
+// TestAESOCB_RFC7253 checks the sample results from RFC 7253 Appendix A.
+func TestAESOCB_RFC7253(t *testing.T) {
+	tests := []struct {
+		key, nonce, aad, plaintext, ciphertext string
+		tagSize                                int
+	}{
+		{
+			key:        "000102030405060708090a0b0c0d0e0f",
+			nonce:      "bbaa99887766554433221100",
+			ciphertext: "785407bfffc8ad9edcc5520ac9111ee6",
+			tagSize:    16,
+		},
+		{
+			key:        "000102030405060708090a0b0c0d0e0f",
+			nonce:      "bbaa99887766554433221101",
+			aad:        "0001020304050607",
+			plaintext:  "0001020304050607",
+			ciphertext: "6820b3657b6f615a5725bda0d3b4eb3a257c9af1f8f03009",
+			tagSize:    16,
+		},
+		{
+			key:        "000102030405060708090a0b0c0d0e0f",
+			nonce:      "bbaa99887766554433221102",
+			aad:        "0001020304050607",
+			ciphertext: "81017f8203f081277152fade694a0a00",
+			tagSize:    16,
+		},
+		{
+			key:        "000102030405060708090a0b0c0d0e0f",
+			nonce:      "bbaa99887766554433221103",
+			plaintext:  "0001020304050607",
+			ciphertext: "45dd69f8f5aae72414054cd1f35d82760b2cd00d2f99bfa9",
+			tagSize:    16,
+		},
+		{
+			key:        "000102030405060708090a0b0c0d0e0f",
+			nonce:      "bbaa99887766554433221104",
+			aad:        "000102030405060708090a0b0c0d0e0f",
+			plaintext:  "000102030405060708090a0b0c0d0e0f",
+			ciphertext: "571d535b60b277188be5147170a9a22c3ad7a4ff3835b8c5701c1ccec8fc3358",
+			tagSize:    16,
+		},
+		{
+			key:        "0f0e0d0c0b0a09080706050403020100",
+			nonce:      "bbaa9988776655443322110d",
+			aad:        "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f2021222324252627",
+			plaintext:  "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f2021222324252627",
+			ciphertext: "1792a4e31e0755fb03e31b22116e6c2ddf9efd6e33d536f1a0124b0a55bae884ed93481529c76b6ad0c515f4d1cdd4fdac4f02aa",
+			tagSize:    12,
+		},
+	}
+
+	for i, tt := range tests {
+		aead, err := goaes.NewOCB(mustHex(t, tt.key), tt.tagSize)
+		if err != nil {
+			t.Fatalf("vector %d: NewOCB failed: %v", i, err)
+		}
+		nonce := mustHex(t, tt.nonce)
+
+		ct := aead.Seal(nil, nonce, mustHex(t, tt.plaintext), mustHex(t, tt.aad))
+		if !bytes.Equal(ct, mustHex(t, tt.ciphertext)) {
+			t.Fatalf("vector %d: ciphertext = %x, want %s", i, ct, tt.ciphertext)
+		}
+
+		pt, err := aead.Open(nil, nonce, ct, mustHex(t, tt.aad))
+		if err != nil {
+			t.Fatalf("vector %d: open failed: %v", i, err)
+		}
+		if !bytes.Equal(pt, mustHex(t, tt.plaintext)) {
+			t.Fatalf("vector %d: plaintext = %x, want %s", i, pt, tt.plaintext)
+		}
+	}
+}
@@ -2,8 +2,6 @@ package goaes
 
 import (
 	"crypto/cipher"
-	"crypto/rand"
-	"errors"
 	"io"
 )
 
@@ -19,25 +17,7 @@ import (
 //
 // Returns: IV prepended to ciphertext (iv||ciphertext).
 func EncryptOFB(key, plaintext []byte) ([]byte, error) {
-	block, err := newCipherBlock(key)
-	if err != nil {
-		return nil, err
-	}
-
-	bs := block.BlockSize()
-	iv := make([]byte, bs)
-	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
-		return nil, err
-	}
-
-	ct := make([]byte, len(plaintext))
-	stream := cipher.NewOFB(block, iv)
-	stream.XORKeyStream(ct, plaintext)
-
-	out := make([]byte, 0, len(iv)+len(ct))
-	out = append(out, iv...)
-	out = append(out, ct...)
-	return out, nil
+	return SealOFB(nil, key, plaintext)
 }
 
 // DecryptOFB decrypts data produced by EncryptOFB.
@@ -49,22 +29,67 @@ func EncryptOFB(key, plaintext []byte) ([]byte, error) {
 //
 // Returns: decrypted plaintext.
 func DecryptOFB(key, ciphertext []byte) ([]byte, error) {
+	return OpenOFB(nil, key, ciphertext)
+}
+
+// SealOFB is the append-style form of EncryptOFB: it appends iv||ciphertext
+// to dst and returns the updated slice. dst may overlap plaintext, e.g.
+// buf[:0] to encrypt buf in place.
+func SealOFB(dst, key, plaintext []byte) ([]byte, error) {
+	return sealStream(dst, key, NewOFB, plaintext)
+}
+
+// OpenOFB is the append-style form of DecryptOFB: it appends the plaintext
+// to dst and returns the updated slice. dst may overlap ciphertext, e.g.
+// buf[:0] to decrypt buf in place.
+func OpenOFB(dst, key, ciphertext []byte) ([]byte, error) {
+	return openStream(dst, key, NewOFB, ciphertext)
+}
+
+// NewOFB returns AES in OFB mode as a cipher.Stream, for use wherever
+// cipher.NewOFB would be used. The same stream encrypts and decrypts.
+//
+// NIST SP 800-38A Warning: The IV must be a nonce, unique for every message
+// under one key. No integrity is provided.
+//
+// Parameters:
+//   - key: 16, 24, or 32 bytes (AES-128, 192, or 256).
+//   - iv: 16 bytes. It is copied.
+func NewOFB(key, iv []byte) (cipher.Stream, error) {
 	block, err := newCipherBlock(key)
 	if err != nil {
 		return nil, err
 	}
-
-	bs := block.BlockSize()
-	if len(ciphertext) < bs {
-		return nil, errors.New("ciphertext too short")
+	if err := validateIVSize(iv); err != nil {
+		return nil, err
 	}
+	return cipher.NewOFB(block, iv), nil
+}
 
-	iv := ciphertext[:bs]
-	ct := ciphertext[bs:]
-
-	pt := make([]byte, len(ct))
-	stream := cipher.NewOFB(block, iv)
-	stream.XORKeyStream(pt, ct)
+// NewOFBWriter returns a writer that encrypts data written to it into w with
+// AES-OFB, for data too large to hold in memory. A random IV is written to w
+// first, so the complete output is byte-compatible with EncryptOFB and can be
+// decrypted with DecryptOFB or NewOFBReader.
+//
+// NIST SP 800-38A Warning: This mode provides Confidentiality ONLY.
+//
+// Parameters:
+//   - w: destination for iv||ciphertext.
+//   - key: 16, 24, or 32 bytes (AES-128, 192, or 256).
+//
+// Returns an error if the IV cannot be generated or written.
+func NewOFBWriter(w io.Writer, key []byte) (io.Writer, error) {
+	return newStreamWriter(w, key, NewOFB)
+}
 
-	return pt, nil
+// NewOFBReader returns a reader that decrypts iv||ciphertext from r, as
+// produced by EncryptOFB or NewOFBWriter. The IV is read immediately.
+//
+// Parameters:
+//   - r: source of iv||ciphertext.
+//   - key: same key used for encryption.
+//
+// Returns an error if the IV cannot be read.
+func NewOFBReader(r io.Reader, key []byte) (io.Reader, error) {
+	return newStreamReader(r, key, NewOFB)
 }
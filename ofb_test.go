@@ -3,6 +3,7 @@ package goaes_test
 import (
 	"bytes"
 	"testing"
+	"testing/iotest"
 
 	goaes "github.com/fawwazid/go-aes"
 )
@@ -44,3 +45,70 @@ func TestAESOFB_EncryptDecrypt(t *testing.T) {
 		}
 	}
 }
+
+func TestAESOFB_NewOFB(t *testing.T) {
+	key := bytes.Repeat([]byte{0x02}, 24)
+	plaintext := []byte("How vexingly quick daft zebras jump")
+
+	ct, err := goaes.EncryptOFB(key, plaintext)
+	if err != nil {
+		t.Fatalf("encrypt failed: %v", err)
+	}
+
+	stream, err := goaes.NewOFB(key, ct[:16])
+	if err != nil {
+		t.Fatalf("NewOFB failed: %v", err)
+	}
+	pt := make([]byte, len(ct)-16)
+	stream.XORKeyStream(pt[:7], ct[16:23])
+	stream.XORKeyStream(pt[7:], ct[23:])
+	if !bytes.Equal(pt, plaintext) {
+		t.Fatalf("plaintext mismatch: got %q", pt)
+	}
+
+	if _, err := goaes.NewOFB(key, nil); err == nil {
+		t.Fatal("expected error for missing IV")
+	}
+}
+
+func TestAESOFB_StreamWriterReader(t *testing.T) {
+	key := bytes.Repeat([]byte{0x32}, 32)
+	plaintext := bytes.Repeat([]byte("output feedback "), 5000)
+
+	// Written in uneven pieces, the output is the OFB one-shot format.
+	var buf bytes.Buffer
+	w, err := goaes.NewOFBWriter(&buf, key)
+	if err != nil {
+		t.Fatalf("NewOFBWriter failed: %v", err)
+	}
+	for p := plaintext; len(p) > 0; {
+		n := min(len(p), 1337)
+		if _, err := w.Write(p[:n]); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+		p = p[n:]
+	}
+	pt, err := goaes.DecryptOFB(key, buf.Bytes())
+	if err != nil || !bytes.Equal(pt, plaintext) {
+		t.Fatalf("DecryptOFB of writer output failed: %v", err)
+	}
+
+	ct, err := goaes.EncryptOFB(key, plaintext)
+	if err != nil {
+		t.Fatalf("encrypt failed: %v", err)
+	}
+	r, err := goaes.NewOFBReader(iotest.OneByteReader(bytes.NewReader(ct)), key)
+	if err != nil {
+		t.Fatalf("NewOFBReader failed: %v", err)
+	}
+	if err := iotest.TestReader(r, plaintext); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := goaes.NewOFBReader(bytes.NewReader(ct[:10]), key); err == nil {
+		t.Fatal("expected error for truncated IV")
+	}
+	if _, err := goaes.NewOFBWriter(&buf, key[:10]); err == nil {
+		t.Fatal("expected error for invalid key size")
+	}
+}
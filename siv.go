@@ -0,0 +1,200 @@
+package goaes
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/subtle"
+	"errors"
+)
+
+const (
+	sivTagSize = 16
+	// sivMaxComponents is the RFC 5297 limit on associated-data components,
+	// counting the nonce (S2V accepts at most 127 inputs including the plaintext).
+	sivMaxComponents = 126
+)
+
+// EncryptSIV encrypts plaintext using AES-SIV (RFC 5297).
+//
+// Deterministic Authenticated Encryption: with a nil nonce, the same key,
+// associated data and plaintext always produce the same ciphertext. This
+// allows equality lookups and deduplication, but it also reveals when two
+// encrypted values are equal. Supply a nonce to make it randomized.
+//
+// Parameters:
+//   - key: 32, 48 or 64 bytes (AES-SIV-CMAC-256/384/512). The first half is the
+//     MAC key and the second half the CTR key; see GenerateSIVKeyForAES.
+//   - plaintext: Data to be encrypted.
+//   - nonce: optional; nil or empty for deterministic encryption.
+//   - additionalData: zero or more associated-data components, authenticated
+//     separately and in order (not concatenated).
+//
+// Returns: synthetic IV||ciphertext (16 bytes longer than plaintext).
+func EncryptSIV(key, plaintext, nonce []byte, additionalData ...[]byte) ([]byte, error) {
+	return SealSIV(nil, key, plaintext, nonce, additionalData...)
+}
+
+// DecryptSIV decrypts data produced by EncryptSIV.
+// It expects the synthetic IV to be prepended to the ciphertext.
+//
+// Parameters:
+//   - key: same key used for encryption.
+//   - ciphertext: synthetic IV||ciphertext.
+//   - nonce: same nonce used for encryption (nil if none).
+//   - additionalData: same associated-data components, in the same order.
+//
+// Returns: decrypted plaintext.
+func DecryptSIV(key, ciphertext, nonce []byte, additionalData ...[]byte) ([]byte, error) {
+	return OpenSIV(nil, key, ciphertext, nonce, additionalData...)
+}
+
+// SealSIV is the append-style form of EncryptSIV: it appends synthetic
+// IV||ciphertext to dst and returns the updated slice. dst may overlap
+// plaintext, e.g. buf[:0] to encrypt buf in place; nonce and additionalData
+// must not overlap dst.
+func SealSIV(dst, key, plaintext, nonce []byte, additionalData ...[]byte) ([]byte, error) {
+	macKey, ctrBlock, err := newSIV(key)
+	if err != nil {
+		return nil, err
+	}
+
+	components, err := sivComponents(nonce, additionalData)
+	if err != nil {
+		return nil, err
+	}
+
+	v := s2v(macKey, components, plaintext)
+
+	ret, out := sliceForAppend(dst, sivTagSize+len(plaintext))
+	body := out[sivTagSize:]
+	if anyOverlap(out, plaintext) {
+		plaintext = body[:copy(body, plaintext)]
+	}
+	copy(out, v[:])
+	sivCTR(ctrBlock, &v, body, plaintext)
+	return ret, nil
+}
+
+// OpenSIV is the append-style form of DecryptSIV: it appends the plaintext
+// to dst and returns the updated slice. dst may overlap ciphertext, e.g.
+// buf[:0] to decrypt buf in place.
+func OpenSIV(dst, key, ciphertext, nonce []byte, additionalData ...[]byte) ([]byte, error) {
+	macKey, ctrBlock, err := newSIV(key)
+	if err != nil {
+		return nil, err
+	}
+
+	components, err := sivComponents(nonce, additionalData)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ciphertext) < sivTagSize {
+		return nil, errors.New("ciphertext too short")
+	}
+
+	var v [sivTagSize]byte
+	copy(v[:], ciphertext[:sivTagSize])
+
+	body := ciphertext[sivTagSize:]
+	ret, pt := sliceForAppend(dst, len(body))
+	if anyOverlap(pt, ciphertext) {
+		body = pt[:copy(pt, body)]
+	}
+	sivCTR(ctrBlock, &v, pt, body)
+
+	expected := s2v(macKey, components, pt)
+	if subtle.ConstantTimeCompare(expected[:], v[:]) != 1 {
+		clear(pt)
+		return nil, errOpen
+	}
+	return ret, nil
+}
+
+// GenerateSIVKeyForAES generates a combined AES-SIV key.
+// `bits` is the AES key size in bits (128, 192, 256). The returned key
+// length will be twice the AES key length (32, 48, 64 bytes).
+func GenerateSIVKeyForAES(bits int) ([]byte, error) {
+	perKeyBytes, err := aesKeyBytesFromBits(bits)
+	if err != nil {
+		return nil, err
+	}
+	return GenerateRandomBytes(perKeyBytes * 2)
+}
+
+// newSIV validates the key and splits it into the S2V (CMAC) key and the CTR cipher.
+func newSIV(key []byte) (*cmacKey, cipher.Block, error) {
+	if err := validateSIVKeySize(key); err != nil {
+		return nil, nil, err
+	}
+
+	half := len(key) / 2
+	macBlock, err := aes.NewCipher(key[:half])
+	if err != nil {
+		return nil, nil, err
+	}
+	ctrBlock, err := aes.NewCipher(key[half:])
+	if err != nil {
+		return nil, nil, err
+	}
+	return newCMACKey(macBlock), ctrBlock, nil
+}
+
+// sivComponents returns the S2V header vector: the associated-data
+// components followed by the nonce, if any (RFC 5297, Section 3).
+func sivComponents(nonce []byte, additionalData [][]byte) ([][]byte, error) {
+	components := additionalData
+	if len(nonce) > 0 {
+		components = append(additionalData[:len(additionalData):len(additionalData)], nonce)
+	}
+	if len(components) > sivMaxComponents {
+		return nil, errors.New("too many SIV associated-data components")
+	}
+	return components, nil
+}
+
+// s2v implements the S2V pseudo-random function (RFC 5297, Section 2.4).
+func s2v(k *cmacKey, components [][]byte, plaintext []byte) [16]byte {
+	var zero [16]byte
+	d := k.sum(zero[:])
+	for _, c := range components {
+		d = gfDouble(&d)
+		m := k.sum(c)
+		subtle.XORBytes(d[:], d[:], m[:])
+	}
+
+	if n := len(plaintext); n >= 16 {
+		// T = plaintext xorend D. The leading blocks are chained directly and
+		// only the final 17 to 32 bytes are copied to apply the xorend.
+		split := 0
+		if n > 16 {
+			split = (n - 17) / 16 * 16
+		}
+		var x [16]byte
+		for i := 0; i < split; i += 16 {
+			subtle.XORBytes(x[:], x[:], plaintext[i:i+16])
+			k.block.Encrypt(x[:], x[:])
+		}
+		var tail [32]byte
+		m := copy(tail[:], plaintext[split:])
+		end := tail[m-16 : m]
+		subtle.XORBytes(end, end, d[:])
+		v := k.sumFrom(x, tail[:m])
+		clear(tail[:])
+		return v
+	}
+
+	d = gfDouble(&d)
+	subtle.XORBytes(d[:], d[:], plaintext)
+	d[len(plaintext)] ^= 0x80
+	return k.sum(d[:])
+}
+
+// sivCTR applies AES-CTR keyed by the second half of the SIV key, using the
+// synthetic IV with bits 31 and 63 cleared as the initial counter.
+func sivCTR(block cipher.Block, v *[16]byte, dst, src []byte) {
+	q := *v
+	q[8] &= 0x7f
+	q[12] &= 0x7f
+	cipher.NewCTR(block, q[:]).XORKeyStream(dst, src)
+}
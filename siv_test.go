@@ -0,0 +1,138 @@
+package goaes_test
+
+import (
+	"bytes"
+	"testing"
+
+	goaes "github.com/fawwazid/go-aes"
+)
+
+func TestAESSIV_EncryptDecrypt(t *testing.T) {
+	plaintext := []byte("4111-1111-1111-1111")
+	ad := [][]byte{[]byte("table:cards"), []byte("column:pan")}
+
+	for _, bits := range []int{128, 192, 256} {
+		key, err := goaes.GenerateSIVKeyForAES(bits)
+		if err != nil {
+			t.Fatalf("key generation failed for %d bits: %v", bits, err)
+		}
+		if len(key) != bits/4 {
+			t.Fatalf("key len = %d, want %d", len(key), bits/4)
+		}
+
+		ct, err := goaes.EncryptSIV(key, plaintext, nil, ad...)
+		if err != nil {
+			t.Fatalf("encrypt failed for key len %d: %v", len(key), err)
+		}
+
+		// deterministic: same inputs give the same ciphertext
+		ct2, err := goaes.EncryptSIV(key, plaintext, nil, ad...)
+		if err != nil {
+			t.Fatalf("encrypt failed for key len %d: %v", len(key), err)
+		}
+		if !bytes.Equal(ct, ct2) {
+			t.Fatalf("expected deterministic ciphertext for key len %d", len(key))
+		}
+
+		pt, err := goaes.DecryptSIV(key, ct, nil, ad...)
+		if err != nil {
+			t.Fatalf("decrypt failed for key len %d: %v", len(key), err)
+		}
+		if !bytes.Equal(pt, plaintext) {
+			t.Fatalf("plaintext mismatch for key len %d", len(key))
+		}
+
+		// tamper detection: flip last byte
+		bad := make([]byte, len(ct))
+		copy(bad, ct)
+		bad[len(bad)-1] ^= 0xFF
+		if _, err := goaes.DecryptSIV(key, bad, nil, ad...); err == nil {
+			t.Fatalf("expected decryption error for tampered ciphertext (key len %d)", len(key))
+		}
+
+		// components are authenticated in order
+		if _, err := goaes.DecryptSIV(key, ct, nil, ad[1], ad[0]); err == nil {
+			t.Fatalf("expected decryption error for reordered AD (key len %d)", len(key))
+		}
+
+		// a nonce randomizes the output and must be supplied to decrypt
+		ctn, err := goaes.EncryptSIV(key, plaintext, []byte("nonce"), ad...)
+		if err != nil {
+			t.Fatalf("nonce encrypt failed for key len %d: %v", len(key), err)
+		}
+		if bytes.Equal(ctn, ct) {
+			t.Fatalf("expected nonce to change ciphertext (key len %d)", len(key))
+		}
+		if _, err := goaes.DecryptSIV(key, ctn, nil, ad...); err == nil {
+			t.Fatalf("expected decryption error without nonce (key len %d)", len(key))
+		}
+	}
+}
+
+func TestAESSIV_InvalidKey(t *testing.T) {
+	key := make([]byte, 16)
+
+	if _, err := goaes.EncryptSIV(key, []byte("secret"), nil); err == nil {
+		t.Error("expected error for invalid key size in EncryptSIV")
+	}
+	if _, err := goaes.DecryptSIV(key, make([]byte, 32), nil); err == nil {
+		t.Error("expected error for invalid key size in DecryptSIV")
+	}
+	if _, err := goaes.DecryptSIV(make([]byte, 32), []byte("short"), nil); err == nil {
+		t.Error("expected error for short ciphertext in DecryptSIV")
+	}
+}
+
+// TestAESSIV_RFC5297 checks the RFC 5297 Appendix A examples.
+func TestAESSIV_RFC5297(t *testing.T) {
+	tests := []struct {
+		name, key, nonce, plaintext, output string
+		ad                                  []string
+	}{
+		{
+			name:      "A.1 deterministic",
+			key:       "fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff",
+			ad:        []string{"101112131415161718191a1b1c1d1e1f2021222324252627"},
+			plaintext: "112233445566778899aabbccddee",
+			output:    "85632d07c6e8f37f950acd320a2ecc9340c02b9690c4dc04daef7f6afe5c",
+		},
+		{
+			name: "A.2 nonce-based",
+			key:  "7f7e7d7c7b7a79787776757473727170404142434445464748494a4b4c4d4e4f",
+			ad: []string{
+				"00112233445566778899aabbccddeeffdeaddadadeaddadaffeeddccbbaa99887766554433221100",
+				"102030405060708090a0",
+			},
+			nonce:     "09f911029d74e35bd84156c5635688c0",
+			plaintext: "7468697320697320736f6d6520706c61696e7465787420746f20656e6372797074207573696e67205349562d414553",
+			output:    "7bdb6e3b432667eb06f4d14bff2fbd0fcb900f2fddbe404326601965c889bf17dba77ceb094fa663b7a3f748ba8af829ea64ad544a272e9c485b62a3fd5c0d",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key := mustHex(t, tt.key)
+			nonce := mustHex(t, tt.nonce)
+			var ad [][]byte
+			for _, a := range tt.ad {
+				ad = append(ad, mustHex(t, a))
+			}
+
+			ct, err := goaes.EncryptSIV(key, mustHex(t, tt.plaintext), nonce, ad...)
+			if err != nil {
+				t.Fatalf("encrypt failed: %v", err)
+			}
+			if !bytes.Equal(ct, mustHex(t, tt.output)) {
+				t.Fatalf("output = %x, want %s", ct, tt.output)
+			}
+
+			pt, err := goaes.DecryptSIV(key, ct, nonce, ad...)
+			if err != nil {
+				t.Fatalf("decrypt failed: %v", err)
+			}
+			if !bytes.Equal(pt, mustHex(t, tt.plaintext)) {
+				t.Fatalf("plaintext = %x, want %s", pt, tt.plaintext)
+			}
+		})
+	}
+}
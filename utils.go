@@ -8,8 +8,12 @@ import (
 	"encoding/hex"
 	"errors"
 	"io"
+	"unsafe"
 )
 
+// errOpen is returned when an authenticated ciphertext fails verification.
+var errOpen = errors.New("message authentication failed")
+
 // GenerateKey returns a random key of the specified byte length.
 // Allowed AES key lengths are 16 (AES-128), 24 (AES-192), or 32 (AES-256) bytes.
 //
@@ -119,6 +123,14 @@ func validateKeySizeLength(length int) error {
 	return nil
 }
 
+// validateIVSize checks that iv is one AES block long.
+func validateIVSize(iv []byte) error {
+	if len(iv) != aes.BlockSize {
+		return errors.New("invalid IV size: must be 16 bytes")
+	}
+	return nil
+}
+
 // validateXTSKeySize checks if the key size is valid for AES-XTS (32, 48, or 64 bytes).
 func validateXTSKeySize(key []byte) error {
 	if len(key) != 32 && len(key) != 48 && len(key) != 64 {
@@ -127,24 +139,197 @@ func validateXTSKeySize(key []byte) error {
 	return nil
 }
 
+// validateSIVKeySize checks if the key size is valid for AES-SIV (32, 48, or 64 bytes).
+func validateSIVKeySize(key []byte) error {
+	if len(key) != 32 && len(key) != 48 && len(key) != 64 {
+		return errors.New("invalid SIV key size: must be 32, 48, or 64 bytes")
+	}
+	return nil
+}
+
+// validateGCMSIVKeySize checks if the key size is valid for AES-GCM-SIV (16 or 32 bytes).
+func validateGCMSIVKeySize(key []byte) error {
+	if len(key) != 16 && len(key) != 32 {
+		return errors.New("invalid GCM-SIV key size: must be 16 or 32 bytes")
+	}
+	return nil
+}
+
 // HexEncode returns the hex encoding of b.
 func HexEncode(b []byte) string { return hex.EncodeToString(b) }
 
 // HexDecode decodes a hex string into bytes.
 func HexDecode(s string) ([]byte, error) { return hex.DecodeString(s) }
 
-// pkcs7Pad appends PKCS#7 padding to the data.
-func pkcs7Pad(data []byte, blockSize int) []byte {
-	pad := blockSize - (len(data) % blockSize)
-	if pad == 0 {
-		pad = blockSize
+// sliceForAppend extends in by n bytes, reusing its capacity when possible.
+// It returns the extended slice and the tail holding the n new bytes.
+func sliceForAppend(in []byte, n int) (head, tail []byte) {
+	if total := len(in) + n; cap(in) >= total {
+		head = in[:total]
+	} else {
+		head = make([]byte, total)
+		copy(head, in)
+	}
+	tail = head[len(in):]
+	return
+}
+
+// anyOverlap reports whether x and y share memory at any index.
+func anyOverlap(x, y []byte) bool {
+	return len(x) > 0 && len(y) > 0 &&
+		uintptr(unsafe.Pointer(&x[0])) <= uintptr(unsafe.Pointer(&y[len(y)-1])) &&
+		uintptr(unsafe.Pointer(&y[0])) <= uintptr(unsafe.Pointer(&x[len(x)-1]))
+}
+
+// sealPrefixed appends nonce||ciphertext to dst, using a fresh random nonce.
+// If plaintext overlaps the output, it is first moved to where the ciphertext
+// goes and sealed in place.
+func sealPrefixed(dst []byte, aead cipher.AEAD, plaintext, additionalData []byte) ([]byte, error) {
+	ns := aead.NonceSize()
+	ret, out := sliceForAppend(dst, ns+len(plaintext)+aead.Overhead())
+	nonce, body := out[:ns], out[ns:]
+	if anyOverlap(out, plaintext) {
+		plaintext = body[:copy(body, plaintext)]
+	}
+
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	ct := aead.Seal(body[:0], nonce, plaintext, additionalData)
+	return ret[:len(dst)+ns+len(ct)], nil
+}
+
+// openPrefixed opens nonce||ciphertext and appends the plaintext to dst. If
+// the output overlaps ciphertext, it is opened in place and then moved.
+func openPrefixed(dst []byte, aead cipher.AEAD, ciphertext, additionalData []byte) ([]byte, error) {
+	ns := aead.NonceSize()
+	if len(ciphertext) < ns {
+		return nil, errors.New("ciphertext too short")
+	}
+
+	nonce, body := ciphertext[:ns], ciphertext[ns:]
+	ret, out := sliceForAppend(dst, len(body))
+	if anyOverlap(out, ciphertext) {
+		pt, err := aead.Open(body[:0], nonce, body, additionalData)
+		if err != nil {
+			return nil, err
+		}
+		return ret[:len(dst)+copy(out, pt)], nil
+	}
+
+	pt, err := aead.Open(out[:0], nonce, body, additionalData)
+	if err != nil {
+		return nil, err
+	}
+	return ret[:len(dst)+len(pt)], nil
+}
+
+// sealStream appends iv||ciphertext to dst for a stream mode, using a fresh
+// random IV. newStream is one of the stream constructors such as NewCTR.
+func sealStream(dst, key []byte, newStream func(key, iv []byte) (cipher.Stream, error), plaintext []byte) ([]byte, error) {
+	// The key is checked before plaintext is moved, so a failed call leaves
+	// an overlapping buffer intact.
+	if err := validateKeySize(key); err != nil {
+		return nil, err
 	}
-	out := make([]byte, len(data)+pad)
-	copy(out, data)
-	for i := len(data); i < len(out); i++ {
-		out[i] = byte(pad)
+
+	ret, out := sliceForAppend(dst, aes.BlockSize+len(plaintext))
+	iv, body := out[:aes.BlockSize], out[aes.BlockSize:]
+	if anyOverlap(out, plaintext) {
+		plaintext = body[:copy(body, plaintext)]
+	}
+
+	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
+		return nil, err
+	}
+
+	stream, err := newStream(key, iv)
+	if err != nil {
+		return nil, err
+	}
+	stream.XORKeyStream(body, plaintext)
+	return ret, nil
+}
+
+// openStream decrypts iv||ciphertext for a stream mode and appends the
+// plaintext to dst.
+func openStream(dst, key []byte, newStream func(key, iv []byte) (cipher.Stream, error), ciphertext []byte) ([]byte, error) {
+	if err := validateKeySize(key); err != nil {
+		return nil, err
+	}
+	if len(ciphertext) < aes.BlockSize {
+		return nil, errors.New("ciphertext too short")
+	}
+
+	// The stream copies the IV, so the output may overwrite it.
+	stream, err := newStream(key, ciphertext[:aes.BlockSize])
+	if err != nil {
+		return nil, err
+	}
+	body := ciphertext[aes.BlockSize:]
+	ret, out := sliceForAppend(dst, len(body))
+	if anyOverlap(out, ciphertext) {
+		body = out[:copy(out, body)]
+	}
+
+	stream.XORKeyStream(out, body)
+	return ret, nil
+}
+
+// newStreamWriter writes a fresh random IV to w and returns a writer that
+// encrypts into w with the given stream mode, matching sealStream's format.
+func newStreamWriter(w io.Writer, key []byte, newStream func(key, iv []byte) (cipher.Stream, error)) (io.Writer, error) {
+	iv := make([]byte, aes.BlockSize)
+	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
+		return nil, err
+	}
+
+	stream, err := newStream(key, iv)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := w.Write(iv); err != nil {
+		return nil, err
+	}
+	return cipher.StreamWriter{S: stream, W: w}, nil
+}
+
+// newStreamReader reads the IV from r and returns a reader that decrypts the
+// rest of r with the given stream mode, matching openStream's format.
+func newStreamReader(r io.Reader, key []byte, newStream func(key, iv []byte) (cipher.Stream, error)) (io.Reader, error) {
+	if err := validateKeySize(key); err != nil {
+		return nil, err
+	}
+
+	iv := make([]byte, aes.BlockSize)
+	if _, err := io.ReadFull(r, iv); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, errors.New("ciphertext too short")
+		}
+		return nil, err
+	}
+
+	stream, err := newStream(key, iv)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.StreamReader{S: stream, R: r}, nil
+}
+
+// pkcs7PaddedLen returns the length of n bytes of data after PKCS#7 padding.
+func pkcs7PaddedLen(n, blockSize int) int {
+	return n - n%blockSize + blockSize
+}
+
+// pkcs7Fill writes PKCS#7 padding after the first n bytes of buf, whose
+// length must be pkcs7PaddedLen(n, blockSize).
+func pkcs7Fill(buf []byte, n int) {
+	pad := byte(len(buf) - n)
+	for i := n; i < len(buf); i++ {
+		buf[i] = pad
 	}
-	return out
 }
 
 // pkcs7Unpad removes PKCS#7 padding from the data and validates it.
@@ -83,3 +83,13 @@ func TestHexHelpers(t *testing.T) {
 		t.Errorf("Hex mismatch: got %s, want %s", string(decoded), string(data))
 	}
 }
+
+// mustHex decodes a hex test vector or fails the test.
+func mustHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := goaes.HexDecode(s)
+	if err != nil {
+		t.Fatalf("invalid hex %q: %v", s, err)
+	}
+	return b
+}
@@ -0,0 +1,141 @@
+package goaes
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/subtle"
+	"errors"
+)
+
+const (
+	xaesKeySize   = 32
+	xaesNonceSize = 24
+)
+
+// EncryptXAESGCM encrypts plaintext using XAES-256-GCM (C2SP).
+//
+// XAES-256-GCM extends AES-256-GCM to 192-bit nonces by deriving a fresh
+// AES-256 key per message with a CMAC-based KDF (NIST SP 800-108r1 counter
+// mode). Random nonces are then safe for effectively unlimited messages under
+// one key, instead of the ~2^32 limit of EncryptGCM.
+//
+// Parameters:
+//   - key: 32 bytes.
+//   - plaintext: Data to be encrypted.
+//   - aad: Additional Authenticated Data (optional, can be nil).
+//
+// Returns: nonce||ciphertext (24-byte nonce).
+func EncryptXAESGCM(key, plaintext, aad []byte) ([]byte, error) {
+	return SealXAESGCM(nil, key, plaintext, aad)
+}
+
+// DecryptXAESGCM decrypts data produced by EncryptXAESGCM.
+// It expects the 24-byte nonce to be prepended to the ciphertext.
+//
+// Parameters:
+//   - key: same key used for encryption.
+//   - ciphertext: nonce||ciphertext.
+//   - aad: same additional data used for encryption.
+//
+// Returns: decrypted plaintext.
+func DecryptXAESGCM(key, ciphertext, aad []byte) ([]byte, error) {
+	return OpenXAESGCM(nil, key, ciphertext, aad)
+}
+
+// SealXAESGCM is the append-style form of EncryptXAESGCM: it appends
+// nonce||ciphertext to dst and returns the updated slice. dst may overlap
+// plaintext, e.g. buf[:0] to encrypt buf in place; aad must not overlap dst.
+func SealXAESGCM(dst, key, plaintext, aad []byte) ([]byte, error) {
+	aead, err := newXAES(key)
+	if err != nil {
+		return nil, err
+	}
+	return sealPrefixed(dst, aead, plaintext, aad)
+}
+
+// OpenXAESGCM is the append-style form of DecryptXAESGCM: it appends the
+// plaintext to dst and returns the updated slice. dst may overlap
+// ciphertext, e.g. buf[:0] to decrypt buf in place.
+func OpenXAESGCM(dst, key, ciphertext, aad []byte) ([]byte, error) {
+	aead, err := newXAES(key)
+	if err != nil {
+		return nil, err
+	}
+	return openPrefixed(dst, aead, ciphertext, aad)
+}
+
+// NewXAESGCM returns XAES-256-GCM as a cipher.AEAD with a 24-byte nonce and
+// 16-byte tag. Random nonces are safe for effectively unlimited messages.
+//
+// Parameters:
+//   - key: 32 bytes.
+func NewXAESGCM(key []byte) (cipher.AEAD, error) {
+	return newXAES(key)
+}
+
+// xaesGCM implements cipher.AEAD for XAES-256-GCM.
+type xaesGCM struct {
+	mac *cmacKey
+}
+
+// newXAES validates the key and precomputes the CMAC subkey K1.
+func newXAES(key []byte) (*xaesGCM, error) {
+	if len(key) != xaesKeySize {
+		return nil, errors.New("invalid XAES-256-GCM key size: must be 32 bytes")
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return &xaesGCM{mac: newCMACKey(block)}, nil
+}
+
+func (x *xaesGCM) NonceSize() int { return xaesNonceSize }
+
+func (x *xaesGCM) Overhead() int { return 16 }
+
+func (x *xaesGCM) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
+	if len(nonce) != xaesNonceSize {
+		panic("goaes: incorrect nonce length given to XAES-256-GCM")
+	}
+	gcm, err := xaesDeriveGCM(x.mac, nonce)
+	if err != nil {
+		panic("goaes: " + err.Error())
+	}
+	return gcm.Seal(dst, nonce[12:], plaintext, additionalData)
+}
+
+func (x *xaesGCM) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(nonce) != xaesNonceSize {
+		panic("goaes: incorrect nonce length given to XAES-256-GCM")
+	}
+	gcm, err := xaesDeriveGCM(x.mac, nonce)
+	if err != nil {
+		return nil, err
+	}
+	return gcm.Open(dst, nonce[12:], ciphertext, additionalData)
+}
+
+// xaesDeriveGCM derives the per-message key from the first 12 bytes of the
+// nonce and returns AES-256-GCM keyed with it:
+//
+//	Kx = AES(K, [0x00 0x01 'X' 0x00 || N[:12]] xor K1) || AES(K, [0x00 0x02 'X' 0x00 || N[:12]] xor K1)
+func xaesDeriveGCM(mac *cmacKey, nonce []byte) (cipher.AEAD, error) {
+	var kx [xaesKeySize]byte
+	defer clear(kx[:])
+
+	for i := byte(0); i < 2; i++ {
+		var m [16]byte
+		m[1] = i + 1
+		m[2] = 'X'
+		copy(m[4:], nonce[:12])
+		subtle.XORBytes(m[:], m[:], mac.k1[:])
+		mac.block.Encrypt(kx[i*16:], m[:])
+	}
+
+	block, err := aes.NewCipher(kx[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,107 @@
+package goaes_test
+
+import (
+	"bytes"
+	"testing"
+
+	goaes "github.com/fawwazid/go-aes"
+)
+
+func TestXAESGCM_EncryptDecrypt(t *testing.T) {
+	plaintext := []byte("Long-lived keys, billions of messages")
+	aad := []byte("header-aad")
+
+	key, err := goaes.GenerateAESKey(256)
+	if err != nil {
+		t.Fatalf("key generation failed: %v", err)
+	}
+
+	ct, err := goaes.EncryptXAESGCM(key, plaintext, aad)
+	if err != nil {
+		t.Fatalf("encrypt failed: %v", err)
+	}
+	if len(ct) != 24+len(plaintext)+16 {
+		t.Fatalf("ciphertext len = %d, want %d", len(ct), 24+len(plaintext)+16)
+	}
+
+	pt, err := goaes.DecryptXAESGCM(key, ct, aad)
+	if err != nil {
+		t.Fatalf("decrypt failed: %v", err)
+	}
+	if !bytes.Equal(pt, plaintext) {
+		t.Fatal("plaintext mismatch")
+	}
+
+	// tamper detection: the first half of the nonce feeds the key derivation,
+	// the second half is the GCM nonce, and the last byte is in the tag.
+	for _, pos := range []int{0, 12, len(ct) - 1} {
+		bad := make([]byte, len(ct))
+		copy(bad, ct)
+		bad[pos] ^= 0xFF
+		if _, err := goaes.DecryptXAESGCM(key, bad, aad); err == nil {
+			t.Fatalf("expected decryption error for tampered byte %d", pos)
+		}
+	}
+}
+
+func TestXAESGCM_InvalidKey(t *testing.T) {
+	for _, k := range []int{16, 24, 64} {
+		if _, err := goaes.EncryptXAESGCM(make([]byte, k), []byte("secret"), nil); err == nil {
+			t.Errorf("expected error for key len %d in EncryptXAESGCM", k)
+		}
+		if _, err := goaes.DecryptXAESGCM(make([]byte, k), make([]byte, 40), nil); err == nil {
+			t.Errorf("expected error for key len %d in DecryptXAESGCM", k)
+		}
+	}
+	if _, err := goaes.DecryptXAESGCM(make([]byte, 32), make([]byte, 23), nil); err == nil {
+		t.Error("expected error for short ciphertext in DecryptXAESGCM")
+	}
+}
+
+// TestXAESGCM_C2SPVectors checks the test vectors from c2sp.org/XAES-256-GCM.
+func TestXAESGCM_C2SPVectors(t *testing.T) {
+	nonce := []byte("ABCDEFGHIJKLMNOPQRSTUVWX")
+	plaintext := []byte("XAES-256-GCM")
+
+	tests := []struct {
+		key        byte
+		aad        []byte
+		ciphertext string
+	}{
+		{0x01, nil, "ce546ef63c9cc60765923609b33a9a1974e96e52daf2fcf7075e2271"},
+		{0x03, []byte("c2sp.org/XAES-256-GCM"), "986ec1832593df5443a179437fd083bf3fdb41abd740a21f71eb769d"},
+	}
+
+	for _, tt := range tests {
+		key := bytes.Repeat([]byte{tt.key}, 32)
+		in := append(append([]byte{}, nonce...), mustHex(t, tt.ciphertext)...)
+
+		pt, err := goaes.DecryptXAESGCM(key, in, tt.aad)
+		if err != nil {
+			t.Fatalf("key %#x: decrypt failed: %v", tt.key, err)
+		}
+		if !bytes.Equal(pt, plaintext) {
+			t.Fatalf("key %#x: plaintext = %q, want %q", tt.key, pt, plaintext)
+		}
+	}
+}
+
+func TestXAESGCM_NewXAESGCM(t *testing.T) {
+	aead, err := goaes.NewXAESGCM(bytes.Repeat([]byte{0x01}, 32))
+	if err != nil {
+		t.Fatalf("NewXAESGCM failed: %v", err)
+	}
+	if aead.NonceSize() != 24 || aead.Overhead() != 16 {
+		t.Fatalf("NonceSize/Overhead = %d/%d, want 24/16", aead.NonceSize(), aead.Overhead())
+	}
+
+	// First C2SP vector.
+	ct := aead.Seal(nil, []byte("ABCDEFGHIJKLMNOPQRSTUVWX"), []byte("XAES-256-GCM"), nil)
+	if want := mustHex(t, "ce546ef63c9cc60765923609b33a9a1974e96e52daf2fcf7075e2271"); !bytes.Equal(ct, want) {
+		t.Fatalf("ciphertext = %x, want %x", ct, want)
+	}
+
+	if _, err := goaes.NewXAESGCM(make([]byte, 16)); err == nil {
+		t.Fatal("expected error for 16-byte key")
+	}
+}
@@ -2,11 +2,17 @@ package goaes
 
 import (
 	"crypto/aes"
+	"crypto/cipher"
+	"crypto/subtle"
+	"encoding/binary"
 	"errors"
 
 	"golang.org/x/crypto/xts"
 )
 
+// xtsBlockSize is the AES block size used by XTS.
+const xtsBlockSize = 16
+
 // EncryptXTS encrypts plaintext using AES-XTS.
 //
 // NIST SP 800-38E Recommendation: Approved for Storage Devices (Data-at-Rest) ONLY.
@@ -14,11 +20,31 @@ import (
 //
 // Parameters:
 //   - key: twice the length of the underlying AES key (32, 48 or 64 bytes).
-//   - plaintext: Data to be encrypted (must be multiple of 16 bytes).
+//   - plaintext: Data to be encrypted (at least 16 bytes). Lengths that are not a
+//     multiple of 16 use IEEE 1619 ciphertext stealing.
 //   - sectorNum: the tweak (typically the sector or block number).
 //
 // Returns: ciphertext.
 func EncryptXTS(key, plaintext []byte, sectorNum uint64) ([]byte, error) {
+	return SealXTS(nil, key, plaintext, sectorNum)
+}
+
+// DecryptXTS decrypts ciphertext produced by EncryptXTS.
+//
+// Parameters:
+//   - key: same key used for encryption.
+//   - ciphertext: Data to be decrypted (at least 16 bytes).
+//   - sectorNum: same sector number used for encryption.
+//
+// Returns: decrypted plaintext.
+func DecryptXTS(key, ciphertext []byte, sectorNum uint64) ([]byte, error) {
+	return OpenXTS(nil, key, ciphertext, sectorNum)
+}
+
+// SealXTS is the append-style form of EncryptXTS: it appends the ciphertext
+// to dst and returns the updated slice. dst may overlap plaintext, e.g.
+// buf[:0] to encrypt a sector buffer in place.
+func SealXTS(dst, key, plaintext []byte, sectorNum uint64) ([]byte, error) {
 	if err := validateXTSKeySize(key); err != nil {
 		return nil, err
 	}
@@ -28,24 +54,47 @@ func EncryptXTS(key, plaintext []byte, sectorNum uint64) ([]byte, error) {
 		return nil, err
 	}
 
-	if len(plaintext)%16 != 0 {
-		return nil, errors.New("plaintext length must be a multiple of 16 bytes for XTS")
+	if len(plaintext) < xtsBlockSize {
+		return nil, errors.New("plaintext must be at least 16 bytes for XTS")
+	}
+
+	ret, out := sliceForAppend(dst, len(plaintext))
+	if anyOverlap(out, plaintext) {
+		plaintext = out[:copy(out, plaintext)]
 	}
 
-	out := make([]byte, len(plaintext))
-	c.Encrypt(out, plaintext, sectorNum)
-	return out, nil
+	tail := len(plaintext) % xtsBlockSize
+	if tail == 0 {
+		c.Encrypt(out, plaintext, sectorNum)
+		return ret, nil
+	}
+
+	// Ciphertext stealing (IEEE 1619, Section 5.3.2): encrypt all but the
+	// last full block normally, then steal from it to complete the tail.
+	m := len(plaintext) / xtsBlockSize
+	full := (m - 1) * xtsBlockSize
+	if full > 0 {
+		c.Encrypt(out[:full], plaintext[:full], sectorNum)
+	}
+
+	k1, tweakPrev, tweakLast, err := xtsStealTweaks(key, sectorNum, m)
+	if err != nil {
+		return nil, err
+	}
+
+	var cc, pp [xtsBlockSize]byte
+	xtsEncryptBlock(k1, &tweakPrev, cc[:], plaintext[full:full+xtsBlockSize])
+	copy(pp[:], plaintext[full+xtsBlockSize:])
+	copy(pp[tail:], cc[tail:])
+	xtsEncryptBlock(k1, &tweakLast, out[full:full+xtsBlockSize], pp[:])
+	copy(out[full+xtsBlockSize:], cc[:tail])
+	return ret, nil
 }
 
-// DecryptXTS decrypts ciphertext produced by EncryptXTS.
-//
-// Parameters:
-//   - key: same key used for encryption.
-//   - ciphertext: Data to be decrypted.
-//   - sectorNum: same sector number used for encryption.
-//
-// Returns: decrypted plaintext.
-func DecryptXTS(key, ciphertext []byte, sectorNum uint64) ([]byte, error) {
+// OpenXTS is the append-style form of DecryptXTS: it appends the plaintext
+// to dst and returns the updated slice. dst may overlap ciphertext, e.g.
+// buf[:0] to decrypt a sector buffer in place.
+func OpenXTS(dst, key, ciphertext []byte, sectorNum uint64) ([]byte, error) {
 	if err := validateXTSKeySize(key); err != nil {
 		return nil, err
 	}
@@ -55,11 +104,87 @@ func DecryptXTS(key, ciphertext []byte, sectorNum uint64) ([]byte, error) {
 		return nil, err
 	}
 
-	if len(ciphertext)%16 != 0 {
-		return nil, errors.New("ciphertext length must be a multiple of 16 bytes for XTS")
+	if len(ciphertext) < xtsBlockSize {
+		return nil, errors.New("ciphertext must be at least 16 bytes for XTS")
+	}
+
+	ret, out := sliceForAppend(dst, len(ciphertext))
+	if anyOverlap(out, ciphertext) {
+		ciphertext = out[:copy(out, ciphertext)]
+	}
+
+	tail := len(ciphertext) % xtsBlockSize
+	if tail == 0 {
+		c.Decrypt(out, ciphertext, sectorNum)
+		return ret, nil
+	}
+
+	m := len(ciphertext) / xtsBlockSize
+	full := (m - 1) * xtsBlockSize
+	if full > 0 {
+		c.Decrypt(out[:full], ciphertext[:full], sectorNum)
+	}
+
+	k1, tweakPrev, tweakLast, err := xtsStealTweaks(key, sectorNum, m)
+	if err != nil {
+		return nil, err
+	}
+
+	var pp, cc [xtsBlockSize]byte
+	xtsDecryptBlock(k1, &tweakLast, pp[:], ciphertext[full:full+xtsBlockSize])
+	copy(cc[:], ciphertext[full+xtsBlockSize:])
+	copy(cc[tail:], pp[tail:])
+	xtsDecryptBlock(k1, &tweakPrev, out[full:full+xtsBlockSize], cc[:])
+	copy(out[full+xtsBlockSize:], pp[:tail])
+	return ret, nil
+}
+
+// xtsStealTweaks returns the data-encryption cipher and the tweaks for block
+// indices m-1 and m of the given sector, as needed for ciphertext stealing.
+func xtsStealTweaks(key []byte, sectorNum uint64, m int) (cipher.Block, [16]byte, [16]byte, error) {
+	var prev, last [16]byte
+
+	k1, err := aes.NewCipher(key[:len(key)/2])
+	if err != nil {
+		return nil, prev, last, err
+	}
+	k2, err := aes.NewCipher(key[len(key)/2:])
+	if err != nil {
+		return nil, prev, last, err
+	}
+
+	binary.LittleEndian.PutUint64(prev[:8], sectorNum)
+	k2.Encrypt(prev[:], prev[:])
+	for i := 0; i < m-1; i++ {
+		xtsMul2(&prev)
 	}
+	last = prev
+	xtsMul2(&last)
+	return k1, prev, last, nil
+}
+
+// xtsEncryptBlock encrypts one block as C = E(K1, P xor T) xor T.
+func xtsEncryptBlock(k1 cipher.Block, tweak *[16]byte, dst, src []byte) {
+	subtle.XORBytes(dst[:xtsBlockSize], src, tweak[:])
+	k1.Encrypt(dst, dst)
+	subtle.XORBytes(dst[:xtsBlockSize], dst, tweak[:])
+}
 
-	out := make([]byte, len(ciphertext))
-	c.Decrypt(out, ciphertext, sectorNum)
-	return out, nil
+// xtsDecryptBlock decrypts one block as P = D(K1, C xor T) xor T.
+func xtsDecryptBlock(k1 cipher.Block, tweak *[16]byte, dst, src []byte) {
+	subtle.XORBytes(dst[:xtsBlockSize], src, tweak[:])
+	k1.Decrypt(dst, dst)
+	subtle.XORBytes(dst[:xtsBlockSize], dst, tweak[:])
+}
+
+// xtsMul2 multiplies the tweak by alpha in GF(2^128) using the little-endian
+// convention of IEEE 1619.
+func xtsMul2(tweak *[16]byte) {
+	var carryIn byte
+	for j := range tweak {
+		carryOut := tweak[j] >> 7
+		tweak[j] = tweak[j]<<1 | carryIn
+		carryIn = carryOut
+	}
+	tweak[0] ^= 0x87 & -carryIn
 }
@@ -64,3 +64,71 @@ func TestAESXTS_EncryptDecrypt(t *testing.T) {
 		}
 	}
 }
+
+func TestAESXTS_CiphertextStealing(t *testing.T) {
+	key := make([]byte, 64)
+	for i := range key {
+		key[i] = byte(i)
+	}
+	msg := bytes.Repeat([]byte("odd-sized file tail "), 10)
+
+	for _, n := range []int{16, 17, 31, 33, 47, 100, len(msg)} {
+		ct, err := goaes.EncryptXTS(key, msg[:n], 7)
+		if err != nil {
+			t.Fatalf("encrypt failed for len %d: %v", n, err)
+		}
+		if len(ct) != n {
+			t.Fatalf("ciphertext len = %d, want %d", len(ct), n)
+		}
+
+		pt, err := goaes.DecryptXTS(key, ct, 7)
+		if err != nil {
+			t.Fatalf("decrypt failed for len %d: %v", n, err)
+		}
+		if !bytes.Equal(pt, msg[:n]) {
+			t.Fatalf("plaintext mismatch for len %d", n)
+		}
+	}
+
+	if _, err := goaes.EncryptXTS(key, make([]byte, 15), 0); err == nil {
+		t.Error("expected error for plaintext shorter than 16 bytes")
+	}
+	if _, err := goaes.DecryptXTS(key, make([]byte, 15), 0); err == nil {
+		t.Error("expected error for ciphertext shorter than 16 bytes")
+	}
+}
+
+// TestAESXTS_IEEE1619 checks the IEEE 1619 vectors 15 to 18, whose data
+// units end in a partial block.
+func TestAESXTS_IEEE1619(t *testing.T) {
+	key := mustHex(t, "fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0bfbebdbcbbbab9b8b7b6b5b4b3b2b1b0")
+	// The vectors give the data unit sequence number as little-endian bytes 9a78563412.
+	const sector = 0x123456789a
+
+	tests := []struct {
+		plaintext, ciphertext string
+	}{
+		{"000102030405060708090a0b0c0d0e0f10", "6c1625db4671522d3d7599601de7ca09ed"},
+		{"000102030405060708090a0b0c0d0e0f1011", "d069444b7a7e0cab09e24447d24deb1fedbf"},
+		{"000102030405060708090a0b0c0d0e0f101112", "e5df1351c0544ba1350b3363cd8ef4beedbf9d"},
+		{"000102030405060708090a0b0c0d0e0f10111213", "9d84c813f719aa2c7be3f66171c7c5c2edbf9dac"},
+	}
+
+	for _, tt := range tests {
+		ct, err := goaes.EncryptXTS(key, mustHex(t, tt.plaintext), sector)
+		if err != nil {
+			t.Fatalf("encrypt failed: %v", err)
+		}
+		if !bytes.Equal(ct, mustHex(t, tt.ciphertext)) {
+			t.Fatalf("ciphertext = %x, want %s", ct, tt.ciphertext)
+		}
+
+		pt, err := goaes.DecryptXTS(key, ct, sector)
+		if err != nil {
+			t.Fatalf("decrypt failed: %v", err)
+		}
+		if !bytes.Equal(pt, mustHex(t, tt.plaintext)) {
+			t.Fatalf("plaintext = %x, want %s", pt, tt.plaintext)
+		}
+	}
+}